@@ -0,0 +1,115 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RunOperation is what a Run asks Terraform Cloud to do
+// +kubebuilder:validation:Enum=Plan;Apply;Destroy;RefreshOnly
+type RunOperation string
+
+const (
+	// RunOperationPlan plans the configuration of the workspace in a speculative run that is never applied
+	RunOperationPlan RunOperation = "Plan"
+	// RunOperationApply plans and applies the workspace
+	RunOperationApply RunOperation = "Apply"
+	// RunOperationDestroy plans and applies the destruction of every resource in the workspace
+	RunOperationDestroy RunOperation = "Destroy"
+	// RunOperationRefreshOnly updates the state to match the real resources without changing them
+	RunOperationRefreshOnly RunOperation = "RefreshOnly"
+)
+
+// Condition types reported in the Run status
+const (
+	// RunConditionComplete is true when the run finished successfully
+	RunConditionComplete = "Complete"
+	// RunConditionFailed is true when the run errored, was canceled or was discarded outside of the operator
+	RunConditionFailed = "Failed"
+)
+
+// RunSpec defines the desired state of Run
+type RunSpec struct {
+	// Workspace in the same namespace to run in
+	WorkspaceRef corev1.LocalObjectReference `json:"workspaceRef"`
+	// Operation to run
+	Operation RunOperation `json:"operation"`
+	// Message attached to the run in Terraform Cloud
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Seconds after the run finished before the Run is deleted, kept forever when unset
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// RunStatus defines the observed state of Run
+type RunStatus struct {
+	// Workspace ID the run is created in, set before the run is created so an interrupted
+	// reconcile finds the run again instead of creating a second one
+	// +optional
+	WorkspaceID string `json:"workspaceID,omitempty"`
+	// Run ID in Terraform Cloud
+	// +optional
+	RunID string `json:"runID,omitempty"`
+	// Latest known status of the run in Terraform Cloud
+	// +optional
+	RunStatus string `json:"runStatus,omitempty"`
+	// Speculative configuration version uploaded for a Plan run
+	// +optional
+	ConfigVersionID string `json:"configVersionID,omitempty"`
+	// Approval state of an Apply or Destroy run waiting for confirmation
+	// +optional
+	ApprovalState string `json:"approvalState,omitempty"`
+	// Link to the run in the Terraform Cloud UI
+	// +optional
+	URL string `json:"url,omitempty"`
+	// When the operator created the run
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// When the operator saw the run finish
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Resource changes of the run
+	// +optional
+	Changes *RunChanges `json:"changes,omitempty"`
+	// Conditions describe the lifecycle of the run, using the Complete and Failed types
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// Run is the Schema for the runs API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=runs,scope=Namespaced
+// +kubebuilder:printcolumn:name="Workspace",type=string,JSONPath=`.spec.workspaceRef.name`
+// +kubebuilder:printcolumn:name="Operation",type=string,JSONPath=`.spec.operation`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.runStatus`
+// +kubebuilder:printcolumn:name="Changes",type=string,JSONPath=`.status.changes.summary`
+// +kubebuilder:printcolumn:name="Run",type=string,JSONPath=`.status.runID`,priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type Run struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RunSpec   `json:"spec,omitempty"`
+	Status RunStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RunList contains a list of Run
+type RunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Run `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Run{}, &RunList{})
+}
@@ -12,6 +12,122 @@ import (
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// ApplyMode controls whether runs are applied automatically or wait for approval
+// +kubebuilder:validation:Enum=auto;manual
+type ApplyMode string
+
+const (
+	// ApplyModeAuto applies runs as soon as the plan succeeds
+	ApplyModeAuto ApplyMode = "auto"
+	// ApplyModeManual parks runs in the planned state until they are approved or discarded
+	ApplyModeManual ApplyMode = "manual"
+)
+
+const (
+	// ApprovalStateNeedsApproval is set while a run is waiting to be approved or discarded
+	ApprovalStateNeedsApproval = "NeedsApproval"
+	// ApprovalStateApproved is set once the operator applied an approved run
+	ApprovalStateApproved = "Approved"
+	// ApprovalStateDiscarded is set once the operator discarded a run
+	ApprovalStateDiscarded = "Discarded"
+)
+
+const (
+	// ApproveRunAnnotation applies the run whose ID is set as value
+	ApproveRunAnnotation = "app.terraform.io/approve-run"
+	// DiscardRunAnnotation discards the run whose ID is set as value
+	DiscardRunAnnotation = "app.terraform.io/discard-run"
+)
+
+const (
+	// OverridePolicyAnnotation overrides the soft-mandatory policies that failed for the run whose ID is set as value
+	OverridePolicyAnnotation = "app.terraform.io/override-policy"
+)
+
+// ExecutionMode is where the runs of a workspace execute
+// +kubebuilder:validation:Enum=remote;local;agent
+type ExecutionMode string
+
+const (
+	// ExecutionModeRemote runs on Terraform Cloud workers
+	ExecutionModeRemote ExecutionMode = "remote"
+	// ExecutionModeLocal only stores the state in Terraform Cloud
+	ExecutionModeLocal ExecutionMode = "local"
+	// ExecutionModeAgent runs on the agents of an agent pool
+	ExecutionModeAgent ExecutionMode = "agent"
+)
+
+// TagPolicy controls what happens to workspace tags that are not declared in the spec
+// +kubebuilder:validation:Enum=Merge;Exclusive
+type TagPolicy string
+
+const (
+	// TagPolicyMerge keeps tags added outside of the operator
+	TagPolicyMerge TagPolicy = "Merge"
+	// TagPolicyExclusive removes every tag that is not declared in the spec or the labels
+	TagPolicyExclusive TagPolicy = "Exclusive"
+)
+
+// RunSupersedePolicy controls what happens to a run in progress when the spec changes
+// +kubebuilder:validation:Enum=Wait;Cancel;Discard
+type RunSupersedePolicy string
+
+const (
+	// RunSupersedePolicyWait lets the run finish before starting a new one
+	RunSupersedePolicyWait RunSupersedePolicy = "Wait"
+	// RunSupersedePolicyCancel cancels the run if it is still planning, or discards it if it waits for confirmation
+	RunSupersedePolicyCancel RunSupersedePolicy = "Cancel"
+	// RunSupersedePolicyDiscard discards the run if it waits for confirmation and otherwise lets it finish
+	RunSupersedePolicyDiscard RunSupersedePolicy = "Discard"
+)
+
+// DeletionPolicy controls what happens in Terraform Cloud when the Workspace is deleted
+// +kubebuilder:validation:Enum=Destroy;Retain;Orphan
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDestroy destroys the resources and deletes the Terraform Cloud workspace
+	DeletionPolicyDestroy DeletionPolicy = "Destroy"
+	// DeletionPolicyRetain keeps the resources but deletes the Terraform Cloud workspace
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyOrphan leaves both the resources and the Terraform Cloud workspace untouched
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// Condition types reported in the Workspace status
+const (
+	// ConditionReady is true when the workspace is reconciled, its latest run succeeded and outputs are synced
+	ConditionReady = "Ready"
+	// ConditionReconciled is true when Terraform Cloud matches the latest generation of the spec
+	ConditionReconciled = "Reconciled"
+	// ConditionRunSucceeded reports the result of the latest run
+	ConditionRunSucceeded = "RunSucceeded"
+	// ConditionOutputsSynced is true when the outputs of the latest run are stored in the cluster
+	ConditionOutputsSynced = "OutputsSynced"
+	// ConditionDegraded is true when reconciling failed or the latest run errored
+	ConditionDegraded = "Degraded"
+	// ConditionDrifted is true when the last drift check found resources that changed outside of Terraform
+	ConditionDrifted = "Drifted"
+	// ConditionSuspended is true when reconciling is suspended by the spec or by the operator maintenance switch
+	ConditionSuspended = "Suspended"
+	// ConditionNameSynced is false when the Terraform Cloud workspace does not have its desired name,
+	// for example after it was renamed outside of the operator
+	ConditionNameSynced = "NameSynced"
+	// ConditionChangesSynced is false when the resource changes of the current run could not be read
+	ConditionChangesSynced = "ChangesSynced"
+)
+
+// DriftPolicy controls what happens when drift is detected
+// +kubebuilder:validation:Enum=Report;Remediate
+type DriftPolicy string
+
+const (
+	// DriftPolicyReport only reports drifted resources in the status
+	DriftPolicyReport DriftPolicy = "Report"
+	// DriftPolicyRemediate starts a run to bring the drifted resources back to the configuration
+	DriftPolicyRemediate DriftPolicy = "Remediate"
+)
+
 // Module references a Terraform module
 type Module struct {
 	// Any remote module source (version control, registry)
@@ -21,6 +137,67 @@ type Module struct {
 	Version string `json:"version"`
 }
 
+// NamedModule is one of several modules called by the generated configuration
+type NamedModule struct {
+	// Name of the module block, unique in the Workspace
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z_][a-zA-Z0-9_-]*$`
+	Name string `json:"name"`
+	// Any remote module source (version control, registry)
+	Source string `json:"source"`
+	// Module version for registry modules
+	// +optional
+	Version string `json:"version,omitempty"`
+	// Inputs of the module
+	// +optional
+	Inputs []ModuleInput `json:"inputs,omitempty"`
+	// Providers passed to the module, keyed by their name in the module, with <name>.<alias>
+	// of a provider of the Workspace as value
+	// +optional
+	Providers map[string]string `json:"providers,omitempty"`
+}
+
+// Provider configures a provider in the generated configuration
+type Provider struct {
+	// Local name of the provider, such as aws
+	Name string `json:"name"`
+	// Source address of the provider, such as hashicorp/aws
+	// +optional
+	Source string `json:"source,omitempty"`
+	// Version constraint of the provider, such as ~> 3.0
+	// +optional
+	Version string `json:"version,omitempty"`
+	// Alias of an additional configuration of the provider
+	// +optional
+	Alias string `json:"alias,omitempty"`
+	// Arguments of the provider block as HCL expressions, which may reference variables
+	// such as var.region. Strings need to be quoted.
+	// +optional
+	Config map[string]string `json:"config,omitempty"`
+}
+
+// ModuleInput sets an input of a module from a variable of the Workspace or from an output of another module
+type ModuleInput struct {
+	// Input name of the module
+	Name string `json:"name"`
+	// Key of the Workspace variable passed to the input
+	// +optional
+	Variable string `json:"variable,omitempty"`
+	// Output of another module passed to the input, as <module>.<output>
+	// +optional
+	ModuleOutput string `json:"moduleOutput,omitempty"`
+}
+
+// Configuration holds Terraform files uploaded with the module, or instead of it
+type Configuration struct {
+	// Terraform files keyed by file name, which must end with .tf
+	// +optional
+	Files map[string]string `json:"files,omitempty"`
+	// ConfigMaps in the namespace of the Workspace whose keys become files,
+	// with .tf appended to keys that do not end with it
+	// +optional
+	ConfigMapRefs []corev1.LocalObjectReference `json:"configMapRefs,omitempty"`
+}
+
 // VCS holds all the information needed to connect the workspace to a VCS repository
 type VCS struct {
 	// Token ID of the VCS Connection (OAuth Connection Token) to use
@@ -33,6 +210,17 @@ type VCS struct {
 	Branch string `json:"branch"`
 	// Whether submodules should be fetched when cloning the VCS repository (Defaults to false)
 	IngressSubmodules bool `json:"ingress_submodules,omitempty"`
+	// Directory of the repository Terraform runs in, relative to the root of the repository
+	// +optional
+	WorkingDirectory string `json:"working_directory,omitempty"`
+	// Paths of the repository, besides the working directory, whose changes trigger runs
+	// when file triggers are enabled
+	// +optional
+	TriggerPrefixes []string `json:"trigger_prefixes,omitempty"`
+	// Regular expression of the Git tags whose pushes trigger runs, instead of every push to the
+	// branch. Cannot be combined with trigger prefixes or fileTriggersEnabled.
+	// +optional
+	TagsRegex string `json:"tags_regex,omitempty"`
 }
 
 // OutputSpec specifies which values need to be output
@@ -40,7 +228,7 @@ type OutputSpec struct {
 	// Output name
 	// +optional
 	Key string `json:"key"`
-	// Attribute name in module
+	// Attribute name in module, as <module>.<output> when the Workspace has several modules
 	// +optional
 	ModuleOutputName string `json:"moduleOutputName"`
 }
@@ -99,6 +287,259 @@ type Notification struct {
 	Users []string `json:"users,omitempty"`
 }
 
+// DriftDetection configures periodic refresh-only plans that detect drift
+type DriftDetection struct {
+	// How often to check for drift, for example `1h`
+	Interval metav1.Duration `json:"interval"`
+	// What to do when drift is detected: Report or Remediate. The default is `Report`.
+	// +optional
+	Policy DriftPolicy `json:"policy,omitempty"`
+}
+
+// DriftStatus reports the result of drift detection
+type DriftStatus struct {
+	// ID of the latest refresh-only run
+	// +optional
+	RunID string `json:"runID,omitempty"`
+	// Status of the latest refresh-only run
+	// +optional
+	RunStatus string `json:"runStatus,omitempty"`
+	// When the latest refresh-only run was queued
+	// +optional
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+	// Addresses of the resources that drifted
+	// +optional
+	DriftedResources []string `json:"driftedResources,omitempty"`
+	// ID of the run started to remediate the drift found by the latest refresh-only run
+	// +optional
+	RemediationRunID string `json:"remediationRunID,omitempty"`
+	// ID of the speculative configuration version uploaded for the next refresh-only run
+	// +optional
+	ConfigVersionID string `json:"configVersionID,omitempty"`
+}
+
+// CatchUpPolicy controls what happens to scheduled runs missed while the operator was down
+// +kubebuilder:validation:Enum=RunOnce;Skip
+type CatchUpPolicy string
+
+const (
+	// CatchUpPolicyRunOnce starts a single run for all the missed schedules
+	CatchUpPolicyRunOnce CatchUpPolicy = "RunOnce"
+	// CatchUpPolicySkip ignores missed schedules and waits for the next one
+	CatchUpPolicySkip CatchUpPolicy = "Skip"
+)
+
+// Schedule starts runs at fixed times
+type Schedule struct {
+	// Cron expression in the standard five field format, for example `0 3 * * 1`
+	Cron string `json:"cron"`
+	// IANA time zone the cron expression is evaluated in. The default is `UTC`.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// What to do with schedules missed while the operator was down: RunOnce or Skip. The default is `RunOnce`.
+	// +optional
+	CatchUpPolicy CatchUpPolicy `json:"catchUpPolicy,omitempty"`
+}
+
+// RunSource is what triggered a run
+type RunSource string
+
+const (
+	// RunSourceSpecChange is a run started by the operator after the spec changed
+	RunSourceSpecChange RunSource = "SpecChange"
+	// RunSourceSchedule is a run started by the operator on the schedule
+	RunSourceSchedule RunSource = "Schedule"
+	// RunSourceDriftRemediation is a run started by the operator to remediate drift
+	RunSourceDriftRemediation RunSource = "DriftRemediation"
+	// RunSourceVCS is a run started by a new configuration version, usually a commit to the VCS repository
+	RunSourceVCS RunSource = "VCS"
+	// RunSourceUI is a run started from the Terraform Cloud UI
+	RunSourceUI RunSource = "UI"
+	// RunSourceAPI is a run started through the Terraform Cloud API outside of the operator
+	RunSourceAPI RunSource = "API"
+	// RunSourceRunObject is a run started by the operator for a Run object
+	RunSourceRunObject RunSource = "Run"
+)
+
+// RunHistoryEntry describes a past or current run of the workspace
+type RunHistoryEntry struct {
+	// Run ID
+	ID string `json:"id"`
+	// Latest known status of the run
+	Status string `json:"status"`
+	// What triggered the run
+	// +optional
+	Source RunSource `json:"source,omitempty"`
+	// Message of the run
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Link to the run in the Terraform Cloud UI
+	// +optional
+	URL string `json:"url,omitempty"`
+	// When the run was created
+	// +optional
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+	// When the operator saw the run finish
+	// +optional
+	FinishedAt *metav1.Time `json:"finishedAt,omitempty"`
+	// Whether the operator canceled or discarded the run because the spec changed
+	// +optional
+	Superseded bool `json:"superseded,omitempty"`
+}
+
+// ResourceCounts counts the resources added, changed and destroyed by a plan or apply
+type ResourceCounts struct {
+	// Resources to add or added
+	Additions int `json:"additions"`
+	// Resources to change or changed
+	Changes int `json:"changes"`
+	// Resources to destroy or destroyed
+	Destructions int `json:"destructions"`
+}
+
+// RunChanges summarizes the resource changes of a run
+type RunChanges struct {
+	// ID of the run the changes belong to
+	RunID string `json:"runID"`
+	// Whether the plan has changes
+	HasChanges bool `json:"hasChanges"`
+	// Resource changes of the plan
+	Plan ResourceCounts `json:"plan"`
+	// Resource changes of the apply, once the run is applied
+	// +optional
+	Apply *ResourceCounts `json:"apply,omitempty"`
+	// Short summary in the +added ~changed -destroyed format
+	Summary string `json:"summary"`
+}
+
+// CostPolicyAction controls what happens to runs whose cost estimate exceeds the budget
+// +kubebuilder:validation:Enum=Discard;Hold
+type CostPolicyAction string
+
+const (
+	// CostPolicyActionDiscard discards runs over budget
+	CostPolicyActionDiscard CostPolicyAction = "Discard"
+	// CostPolicyActionHold holds runs over budget until they are approved or discarded
+	CostPolicyActionHold CostPolicyAction = "Hold"
+)
+
+// CostPolicy sets a budget on the cost estimate of runs
+type CostPolicy struct {
+	// Maximum increase of the estimated monthly cost a run may cause, for example `100.00`
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	MaxMonthlyDelta string `json:"maxMonthlyDelta"`
+	// What to do with runs over budget: Discard or Hold for approval. The default is `Discard`.
+	// +optional
+	Action CostPolicyAction `json:"action,omitempty"`
+	// Treat runs without a finished cost estimate as within budget instead of over budget, for example
+	// when cost estimation is disabled for the organization or the estimate errored
+	// +optional
+	AllowUnknownCost bool `json:"allowUnknownCost,omitempty"`
+}
+
+// CostEstimateStatus reports the cost estimate of a run
+type CostEstimateStatus struct {
+	// ID of the run the estimate belongs to
+	RunID string `json:"runID"`
+	// Status of the cost estimate
+	Status string `json:"status"`
+	// Estimated monthly cost before the run
+	// +optional
+	PriorMonthlyCost string `json:"priorMonthlyCost,omitempty"`
+	// Estimated monthly cost after the run
+	// +optional
+	ProposedMonthlyCost string `json:"proposedMonthlyCost,omitempty"`
+	// Difference between the proposed and prior monthly cost
+	// +optional
+	DeltaMonthlyCost string `json:"deltaMonthlyCost,omitempty"`
+	// Whether the run exceeds the budget of the cost policy
+	// +optional
+	OverBudget bool `json:"overBudget,omitempty"`
+}
+
+// PolicyResult is the result of a single Sentinel policy
+type PolicyResult struct {
+	// Policy name
+	Name string `json:"name"`
+	// Enforcement level: advisory, soft-mandatory or hard-mandatory
+	EnforcementLevel string `json:"enforcementLevel"`
+	// Whether the policy passed
+	Passed bool `json:"passed"`
+}
+
+// PolicyCheckStatus reports the Sentinel policy checks of a run
+type PolicyCheckStatus struct {
+	// ID of the run the policy checks belong to
+	RunID string `json:"runID"`
+	// Status of the policy checks
+	Status string `json:"status"`
+	// Number of policies that passed
+	Passed int `json:"passed"`
+	// Number of advisory policies that failed
+	AdvisoryFailed int `json:"advisoryFailed"`
+	// Number of soft-mandatory policies that failed
+	SoftFailed int `json:"softFailed"`
+	// Number of hard-mandatory policies that failed
+	HardFailed int `json:"hardFailed"`
+	// Summary of the Sentinel output
+	// +optional
+	Summary string `json:"summary,omitempty"`
+	// Result of each policy
+	// +optional
+	Policies []PolicyResult `json:"policies,omitempty"`
+	// Field manager that set the override-policy annotation, as the API server recorded it in the
+	// managed fields of the Workspace. It names the client, like kubectl-annotate, not the user.
+	// +optional
+	OverriddenBy string `json:"overriddenBy,omitempty"`
+}
+
+// TeamPermissions are the permissions of the custom access level
+type TeamPermissions struct {
+	// Permission on runs: read, plan or apply
+	// +kubebuilder:validation:Enum=read;plan;apply
+	// +optional
+	Runs string `json:"runs,omitempty"`
+	// Permission on variables: none, read or write
+	// +kubebuilder:validation:Enum=none;read;write
+	// +optional
+	Variables string `json:"variables,omitempty"`
+	// Permission on state versions: none, read-outputs, read or write
+	// +kubebuilder:validation:Enum=none;read-outputs;read;write
+	// +optional
+	StateVersions string `json:"stateVersions,omitempty"`
+	// Permission on Sentinel mocks: none or read
+	// +kubebuilder:validation:Enum=none;read
+	// +optional
+	SentinelMocks string `json:"sentinelMocks,omitempty"`
+	// Whether the team can lock and unlock the workspace
+	// +optional
+	WorkspaceLocking bool `json:"workspaceLocking,omitempty"`
+}
+
+// TeamAccess grants a team access to the workspace
+type TeamAccess struct {
+	// Name of the team, or its ID (team-...)
+	Team string `json:"team"`
+	// Access level: read, plan, write, admin or custom
+	// +kubebuilder:validation:Enum=read;plan;write;admin;custom
+	Access string `json:"access"`
+	// Permissions of the custom access level
+	// +optional
+	Permissions *TeamPermissions `json:"permissions,omitempty"`
+}
+
+// TeamAccessStatus is a team access the operator manages
+type TeamAccessStatus struct {
+	// Team as written in the spec
+	Team string `json:"team"`
+	// Resolved team ID
+	TeamID string `json:"teamID"`
+	// Team access ID
+	ID string `json:"id"`
+	// Access level
+	Access string `json:"access"`
+}
+
 // Run Trigger from a source workspace
 type RunTrigger struct {
 	// Name of source workspace that triggers the current workspace
@@ -114,6 +555,18 @@ type WorkspaceSpec struct {
 	// +optional
 	// +nullable
 	Module *Module `json:"module"`
+	// Named modules called by the generated configuration, instead of a single module
+	// +optional
+	Modules []NamedModule `json:"modules,omitempty"`
+	// Providers configured in the generated configuration
+	// +optional
+	Providers []Provider `json:"providers,omitempty"`
+	// Terraform version constraint of the generated configuration, such as >= 1.0
+	// +optional
+	RequiredVersion string `json:"requiredVersion,omitempty"`
+	// Terraform files uploaded with the generated module configuration, or on their own without a module
+	// +optional
+	Configuration *Configuration `json:"configuration,omitempty"`
 	// Details of the VCS repository we want to connect to the workspace
 	// +optional
 	// +nullable
@@ -144,9 +597,98 @@ type WorkspaceSpec struct {
 	// Omit namespace prefix in workspace name
 	// +optional
 	OmitNamespacePrefix bool `json:"omitNamespacePrefix,omitempty"`
+	// Name of the Terraform Cloud workspace, overriding the name template of the operator and omitNamespacePrefix
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_-]+$`
+	// +kubebuilder:validation:MaxLength=90
+	// +optional
+	WorkspaceName string `json:"workspaceName,omitempty"`
+	// Rename the Terraform Cloud workspace to its desired name when it was renamed outside of the operator,
+	// or when the desired name changed. The mismatch is only reported when false.
+	// +optional
+	EnforceName bool `json:"enforceName,omitempty"`
 	// Specifies the agent pool name we wish to use.
 	// +optional
 	AgentPoolName string `json:"agentPoolName,omitempty"`
+	// Whether runs are applied automatically (auto) or wait for approval (manual). The default is `auto`.
+	// +optional
+	ApplyMode ApplyMode `json:"applyMode,omitempty"`
+	// What to do in Terraform Cloud when the Workspace is deleted: Destroy, Retain or Orphan. The default is `Destroy`,
+	// or `Orphan` for adopted workspaces.
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// Name or ID (ws-...) of an existing Terraform Cloud workspace to adopt instead of creating a new one.
+	// Only the settings declared in the spec are managed on an adopted workspace and, unless a deletion
+	// policy is set, deleting the Workspace releases it back to unmanaged.
+	// +optional
+	ExistingWorkspace string `json:"existingWorkspace,omitempty"`
+	// Release the Terraform Cloud workspace back to unmanaged without deleting the Workspace. The operator
+	// drops the workspace ID and the finalizer, leaves the workspace and its resources untouched and stops
+	// reconciling until release is unset, which binds the workspace again.
+	// +optional
+	Release bool `json:"release,omitempty"`
+	// Periodically check whether the real infrastructure drifted from the state
+	// +optional
+	DriftDetection *DriftDetection `json:"driftDetection,omitempty"`
+	// Start runs on a cron schedule
+	// +optional
+	Schedule *Schedule `json:"schedule,omitempty"`
+	// Number of runs kept in the run history of the status. The default is 10.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	RunHistoryLimit *int32 `json:"runHistoryLimit,omitempty"`
+	// Budget for the estimated monthly cost of runs. When set, the operator
+	// confirms runs itself once their cost estimate is within budget.
+	// +optional
+	CostPolicy *CostPolicy `json:"costPolicy,omitempty"`
+	// What to do with a run in progress when the spec changes: Wait, Cancel or Discard. The default is `Wait`.
+	// +optional
+	RunSupersedePolicy RunSupersedePolicy `json:"runSupersedePolicy,omitempty"`
+	// Stop changing the Terraform Cloud workspace and starting runs, while still refreshing the status
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+	// Description of the workspace
+	// +optional
+	Description *string `json:"description,omitempty"`
+	// Where runs execute: remote, local or agent. Agent execution needs an agent pool and is
+	// selected automatically when one is set.
+	// +optional
+	ExecutionMode ExecutionMode `json:"executionMode,omitempty"`
+	// Whether runs triggered by a webhook are queued before a run was queued manually
+	// +optional
+	QueueAllRuns *bool `json:"queueAllRuns,omitempty"`
+	// Whether the workspace allows speculative plans, for example on pull requests
+	// +optional
+	SpeculativeEnabled *bool `json:"speculativeEnabled,omitempty"`
+	// Whether destroy plans can be queued on the workspace
+	// +optional
+	AllowDestroyPlan *bool `json:"allowDestroyPlan,omitempty"`
+	// Whether every workspace in the organization can read the state of this workspace
+	// +optional
+	GlobalRemoteState *bool `json:"globalRemoteState,omitempty"`
+	// Whether VCS pushes only trigger runs when they change files in the working directory
+	// +optional
+	FileTriggersEnabled *bool `json:"fileTriggersEnabled,omitempty"`
+	// Name of the application creating the workspace, shown as "Created via". Terraform Cloud only
+	// accepts it when the workspace is created.
+	// +optional
+	SourceName string `json:"sourceName,omitempty"`
+	// Link to the application creating the workspace. Terraform Cloud only accepts it when the
+	// workspace is created.
+	// +optional
+	SourceURL string `json:"sourceURL,omitempty"`
+	// Tags of the workspace
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+	// Labels of the Workspace whose key starts with this prefix become workspace tags, named after the
+	// rest of the key and the value as name:value, or only the name when the value is empty or true
+	// +optional
+	TagLabelPrefix string `json:"tagLabelPrefix,omitempty"`
+	// Whether tags added outside of the operator are kept (Merge) or removed (Exclusive). The default is `Merge`.
+	// +optional
+	TagPolicy TagPolicy `json:"tagPolicy,omitempty"`
+	// Teams granted access to the workspace
+	// +optional
+	TeamAccess []TeamAccess `json:"teamAccess,omitempty"`
 }
 
 // WorkspaceStatus defines the observed state of Workspace
@@ -156,13 +698,61 @@ type WorkspaceStatus struct {
 	RunStatus string `json:"runStatus"`
 	// Workspace ID
 	WorkspaceID string `json:"workspaceID"`
+	// Name of the Terraform Cloud workspace
+	// +optional
+	WorkspaceName string `json:"workspaceName,omitempty"`
 	// Run ID
 	RunID string `json:"runID"`
+	// Hash of the configuration, VCS repository and variables when the current run was started or, for
+	// runs started outside of the operator, first seen
+	// +optional
+	RunFingerprint string `json:"runFingerprint,omitempty"`
 	// Configuration Version ID
 	ConfigVersionID string `json:"configVersionID"`
+	// Whether a run waits for the configuration of a new VCS repository or branch to be ingressed
+	// +optional
+	VCSRunPending bool `json:"vcsRunPending,omitempty"`
+	// Tags the operator added to the workspace
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+	// Team access the operator granted, with the resolved team IDs
+	// +optional
+	TeamAccess []TeamAccessStatus `json:"teamAccess,omitempty"`
 	// Outputs from state file
 	// +optional
 	Outputs []*OutputStatus `json:"outputs,omitempty"`
+	// Approval state of the current run when applyMode is manual
+	// +optional
+	ApprovalState string `json:"approvalState,omitempty"`
+	// The generation of the spec that was last reconciled
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the current state of the workspace
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Result of drift detection when driftDetection is set
+	// +optional
+	Drift *DriftStatus `json:"drift,omitempty"`
+	// When the latest scheduled run was due
+	// +optional
+	LastScheduledTime *metav1.Time `json:"lastScheduledTime,omitempty"`
+	// When the next scheduled run is due
+	// +optional
+	NextScheduledTime *metav1.Time `json:"nextScheduledTime,omitempty"`
+	// Most recent runs of the workspace, newest first
+	// +optional
+	RunHistory []RunHistoryEntry `json:"runHistory,omitempty"`
+	// Resource changes of the current run, once it is planned
+	// +optional
+	Changes *RunChanges `json:"changes,omitempty"`
+	// Cost estimate of the current run
+	// +optional
+	CostEstimate *CostEstimateStatus `json:"costEstimate,omitempty"`
+	// Sentinel policy checks of the current run
+	// +optional
+	PolicyCheck *PolicyCheckStatus `json:"policyCheck,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -171,8 +761,14 @@ type WorkspaceStatus struct {
 // +k8s:openapi-gen=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:path=workspaces,scope=Namespaced
-// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
 // +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.runStatus`
+// +kubebuilder:printcolumn:name="Changes",type=string,JSONPath=`.status.changes.summary`
+// +kubebuilder:printcolumn:name="Suspended",type=string,JSONPath=`.status.conditions[?(@.type=="Suspended")].status`,priority=1
+// +kubebuilder:printcolumn:name="Approval",type=string,JSONPath=`.status.approvalState`,priority=1
+// +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].message`,priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type Workspace struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -0,0 +1,48 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package controllers
+
+import (
+	"context"
+
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/hashicorp/terraform-k8s/workspacehelper"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// addRun adds a new Run Controller to mgr with r as the reconcile.Reconciler, reconciling up to
+// maxConcurrentReconciles Runs at once
+func addRun(mgr manager.Manager, r reconcile.Reconciler, maxConcurrentReconciles int) error {
+	c, err := controller.New("run-controller", mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource Run
+	return c.Watch(&source.Kind{Type: &appv1alpha1.Run{}}, &handler.EnqueueRequestForObject{})
+}
+
+// NewRunReconciler returns a new reconcile.Reconciler for Run objects
+func NewRunReconciler(mgr manager.Manager, options workspacehelper.Options) reconcile.Reconciler {
+	return &RunReconciler{
+		helper: workspacehelper.NewRunHelper(mgr, options),
+	}
+}
+
+type RunReconciler struct {
+	helper reconcile.Reconciler
+}
+
+// +kubebuilder:rbac:groups=app.terraform.io,resources=runs,verbs=get;list;watch;create;update;patch;delete,namespace=terraform-k8s
+// +kubebuilder:rbac:groups=app.terraform.io,resources=runs/status,verbs=get;update;patch,namespace=terraform-k8s
+func (r *RunReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	return r.helper.Reconcile(context.TODO(), req)
+}
@@ -17,10 +17,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
-// add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler) error {
+// add adds a new Controller to mgr with r as the reconcile.Reconciler, reconciling up to
+// maxConcurrentReconciles Workspaces at once
+func add(mgr manager.Manager, r reconcile.Reconciler, maxConcurrentReconciles int) error {
 	// Create a new controller
-	c, err := controller.New("workspace-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("workspace-controller", mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+	})
 	if err != nil {
 		return err
 	}
@@ -42,16 +46,19 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	return nil
 }
 
-// Add creates a new Workspace Controller and adds it to the Manager. The Manager will set fields on the Controller
-// and Start it when the Manager is Started.
-func Add(mgr manager.Manager) error {
-	return add(mgr, NewWorkspaceReconciler(mgr))
+// Add creates the Workspace and Run Controllers and adds them to the Manager. The Manager will set fields on the
+// Controllers and Start them when the Manager is Started.
+func Add(mgr manager.Manager, options workspacehelper.Options) error {
+	if err := add(mgr, NewWorkspaceReconciler(mgr, options), options.MaxConcurrentReconciles); err != nil {
+		return err
+	}
+	return addRun(mgr, NewRunReconciler(mgr, options), options.MaxConcurrentReconciles)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func NewWorkspaceReconciler(mgr manager.Manager) reconcile.Reconciler {
+func NewWorkspaceReconciler(mgr manager.Manager, options workspacehelper.Options) reconcile.Reconciler {
 	return &WorkspaceReconciler{
-		helper: workspacehelper.NewWorkspaceHelper(mgr),
+		helper: workspacehelper.NewWorkspaceHelper(mgr, options),
 	}
 }
 
@@ -5,9 +5,11 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -16,6 +18,7 @@ import (
 
 	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
 	"github.com/hashicorp/terraform-k8s/controllers"
+	"github.com/hashicorp/terraform-k8s/workspacehelper"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -35,11 +38,22 @@ func main() {
 	var metricsAddr string
 	var namespaceName string
 	var enableLeaderElection bool
+	var maintenanceConfigMap string
+	var workspaceNameTemplate string
+	var clusterName string
+	var maxConcurrentReconciles int
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8383", "The address the metric endpoint binds to.")
 	flag.StringVar(&namespaceName, "k8s-watch-namespace", "", "Name of the namespace to watch.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&maintenanceConfigMap, "maintenance-configmap", "",
+		"Name of a ConfigMap in the operator namespace that suspends every Workspace while its suspend key is true.")
+	flag.StringVar(&workspaceNameTemplate, "workspace-name-template", workspacehelper.DefaultWorkspaceNameTemplate,
+		"Template of the names of Terraform Cloud workspaces, using {{.ClusterName}}, {{.Namespace}} and {{.Name}}.")
+	flag.StringVar(&clusterName, "cluster-name", "", "Name of the cluster used by the workspace name template.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"Number of Workspaces and Runs reconciled at once.")
 	opts := zap.Options{}
 	opts.BindFlags(flag.CommandLine)
 	err := flag.Set("zap-devel", "true")
@@ -51,6 +65,28 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	options := workspacehelper.Options{}
+	if maintenanceConfigMap != "" {
+		// The Role of the operator only grants access to ConfigMaps of its own namespace
+		namespace := os.Getenv("POD_NAMESPACE")
+		if namespace == "" {
+			setupLog.Error(fmt.Errorf("POD_NAMESPACE is not set"), "maintenance-configmap needs the operator namespace")
+			os.Exit(1)
+		}
+		options.MaintenanceConfigMap = types.NamespacedName{Namespace: namespace, Name: maintenanceConfigMap}
+	}
+	options.WorkspaceNameTemplate, err = workspacehelper.ParseWorkspaceNameTemplate(workspaceNameTemplate)
+	if err != nil {
+		setupLog.Error(err, "invalid workspace-name-template flag")
+		os.Exit(1)
+	}
+	options.ClusterName = clusterName
+	if maxConcurrentReconciles < 1 {
+		setupLog.Error(fmt.Errorf("expected at least 1, got %d", maxConcurrentReconciles), "invalid max-concurrent-reconciles flag")
+		os.Exit(1)
+	}
+	options.MaxConcurrentReconciles = maxConcurrentReconciles
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Namespace:          namespaceName,
 		Scheme:             scheme,
@@ -64,7 +100,15 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := controllers.Add(mgr); err != nil {
+	if options.MaintenanceConfigMap.Name != "" {
+		options.MaintenanceReader, err = workspacehelper.NewMaintenanceReader(mgr, options.MaintenanceConfigMap)
+		if err != nil {
+			setupLog.Error(err, "unable to watch maintenance ConfigMap")
+			os.Exit(1)
+		}
+	}
+
+	if err := controllers.Add(mgr, options); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Workspace")
 		os.Exit(1)
 	}
@@ -17,15 +17,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
-func configMapForTerraform(name string, namespace string, template []byte) *corev1.ConfigMap {
+func configMapForTerraform(name string, namespace string, data map[string]string) *corev1.ConfigMap {
 	return &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
 		},
-		Data: map[string]string{
-			TerraformConfigMap: string(template),
-		},
+		Data: data,
 	}
 }
 
@@ -40,12 +38,12 @@ func secretForOutputs(name string, namespace string, outputs []*v1alpha1.OutputS
 	}
 }
 
-// UpsertTerraformConfig creates a ConfigMap for the Terraform template if it doesn't exist already
-func (r *WorkspaceHelper) UpsertTerraformConfig(w *v1alpha1.Workspace, template []byte) (bool, error) {
+// UpsertTerraformConfig creates a ConfigMap for the Terraform configuration if it doesn't exist already
+func (r *WorkspaceHelper) UpsertTerraformConfig(w *v1alpha1.Workspace, data map[string]string) (bool, error) {
 	found := &corev1.ConfigMap{}
 	err := r.client.Get(context.TODO(), types.NamespacedName{Name: w.Name, Namespace: w.Namespace}, found)
 	if err != nil && k8serrors.IsNotFound(err) {
-		configMap := configMapForTerraform(w.Name, w.Namespace, template)
+		configMap := configMapForTerraform(w.Name, w.Namespace, data)
 		err := controllerutil.SetControllerReference(w, configMap, r.scheme)
 		if err != nil {
 			return false, err
@@ -61,11 +59,11 @@ func (r *WorkspaceHelper) UpsertTerraformConfig(w *v1alpha1.Workspace, template
 		return false, err
 	}
 
-	if found.Data[TerraformConfigMap] == string(template) {
+	if reflect.DeepEqual(found.Data, data) {
 		return false, nil
 	}
 
-	found.Data[TerraformConfigMap] = string(template)
+	found.Data = data
 	if err := r.client.Update(context.TODO(), found); err != nil {
 		r.reqLogger.Error(err, "Failed to update Terraform ConfigMap", "Namespace", w.Namespace, "Name", w.Name)
 		return false, err
@@ -73,6 +71,53 @@ func (r *WorkspaceHelper) UpsertTerraformConfig(w *v1alpha1.Workspace, template
 	return true, nil
 }
 
+// GetRunLogsConfigMap retrieves the data of the ConfigMap holding the logs of the latest run, if any
+func (r *WorkspaceHelper) GetRunLogsConfigMap(w *v1alpha1.Workspace) (map[string]string, error) {
+	found := &corev1.ConfigMap{}
+	name := fmt.Sprintf("%s-logs", w.Name)
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: w.Namespace}, found)
+	if err != nil && k8serrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		r.reqLogger.Error(err, "Failed to get run logs ConfigMap")
+		return nil, err
+	}
+	return found.Data, nil
+}
+
+// UpsertRunLogs creates or updates the ConfigMap holding the logs of the latest run
+func (r *WorkspaceHelper) UpsertRunLogs(w *v1alpha1.Workspace, data map[string]string) error {
+	found := &corev1.ConfigMap{}
+	name := fmt.Sprintf("%s-logs", w.Name)
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: w.Namespace}, found)
+	if err != nil && k8serrors.IsNotFound(err) {
+		configMap := configMapForTerraform(name, w.Namespace, data)
+		err := controllerutil.SetControllerReference(w, configMap, r.scheme)
+		if err != nil {
+			return err
+		}
+		r.reqLogger.Info("Writing run logs to new ConfigMap")
+		if err := r.client.Create(context.TODO(), configMap); err != nil {
+			r.reqLogger.Error(err, "Failed to create run logs ConfigMap")
+			return err
+		}
+		return nil
+	} else if err != nil {
+		r.reqLogger.Error(err, "Failed to get run logs ConfigMap")
+		return err
+	}
+
+	if reflect.DeepEqual(found.Data, data) {
+		return nil
+	}
+	found.Data = data
+	if err := r.client.Update(context.TODO(), found); err != nil {
+		r.reqLogger.Error(err, "Failed to update run logs ConfigMap", "Namespace", w.Namespace, "Name", name)
+		return err
+	}
+	return nil
+}
+
 // GetConfigMapForVariable retrieves the configmap value associated with the variable
 func (r *WorkspaceHelper) GetConfigMapForVariable(namespace string, variable *v1alpha1.Variable) error {
 	if variable.Sensitive || variable.Value != "" {
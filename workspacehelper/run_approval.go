@@ -0,0 +1,96 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"fmt"
+
+	tfc "github.com/hashicorp/go-tfe"
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// confirmRun applies, discards or holds an Apply or Destroy run waiting for confirmation, following
+// the apply mode and cost policy of its Workspace like the runs the Workspace queues itself.
+func (r *RunHelper) confirmRun(instance *appv1alpha1.Run, workspace *appv1alpha1.Workspace) error {
+	runID := instance.Status.RunID
+	actions, err := r.tfclient.GetRunActions(runID)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not get actions of run", "RunID", runID)
+		return err
+	}
+	if !actions.IsConfirmable {
+		return nil
+	}
+
+	hold := ""
+	if !isAutoApply(workspace) {
+		hold = "the Workspace uses applyMode manual"
+	}
+	if policy := workspace.Spec.CostPolicy; policy != nil {
+		costEstimate, err := r.tfclient.GetCostEstimate(runID)
+		if err != nil {
+			r.reqLogger.Error(err, "Could not get cost estimate of run", "RunID", runID)
+			return err
+		}
+		estimate := costEstimateStatus(runID, costEstimate)
+		if !costEstimateDone(estimate.Status) {
+			return nil
+		}
+		over, err := overBudget(policy, estimate)
+		if err != nil {
+			r.reqLogger.Error(err, "Could not check cost estimate", "RunID", runID)
+			return err
+		}
+		if over && policy.Action != appv1alpha1.CostPolicyActionHold {
+			comment := fmt.Sprintf("%s, over the cost budget of %s", TerraformOperator, policy.MaxMonthlyDelta)
+			if err := r.tfclient.DiscardRun(runID, comment); err != nil {
+				r.reqLogger.Error(err, "Could not discard run over budget", "RunID", runID)
+				return err
+			}
+			r.recorder.Event(instance, corev1.EventTypeWarning, "RunEvent",
+				fmt.Sprintf("Discarded run %s, it is over the cost budget of Workspace %s", runID, workspace.Name))
+			return nil
+		}
+		if over {
+			hold = fmt.Sprintf("over the cost budget of %s", policy.MaxMonthlyDelta)
+		}
+	}
+
+	if hold == "" {
+		comment := fmt.Sprintf("%s, Run %s", TerraformOperator, instance.Name)
+		if err := r.tfclient.ApplyRun(runID, comment); err != nil {
+			r.reqLogger.Error(err, "Could not apply run", "RunID", runID)
+			return err
+		}
+		r.recorder.Event(instance, corev1.EventTypeNormal, "RunEvent", fmt.Sprintf("Applying run %s", runID))
+		return nil
+	}
+	return r.approvalGate(instance).reconcile(runID, fmt.Sprintf(" (%s)", hold))
+}
+
+func (r *RunHelper) approvalGate(instance *appv1alpha1.Run) *approvalGate {
+	return &approvalGate{
+		client:    r.client,
+		tfclient:  r.tfclient,
+		recorder:  r.recorder,
+		reqLogger: r.reqLogger,
+		object:    instance,
+		kind:      "Run",
+		state:     &instance.Status.ApprovalState,
+	}
+}
+
+// discardPolicySoftFailedRun discards a run that failed soft-mandatory policies. Policy overrides
+// are only reconciled for the runs a Workspace queues itself, see reconcilePolicyOverride.
+func (r *RunHelper) discardPolicySoftFailedRun(instance *appv1alpha1.Run) error {
+	runID := instance.Status.RunID
+	comment := fmt.Sprintf("%s, Run %s failed soft-mandatory policies", TerraformOperator, instance.Name)
+	if err := r.tfclient.DiscardRun(runID, comment); err != nil {
+		r.reqLogger.Error(err, "Could not discard run that failed policies", "RunID", runID)
+		return err
+	}
+	instance.Status.RunStatus = string(tfc.RunDiscarded)
+	return nil
+}
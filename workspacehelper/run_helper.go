@@ -0,0 +1,386 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+	tfc "github.com/hashicorp/go-tfe"
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const runPollInterval = 15 * time.Second
+
+// Condition reasons only set on Run objects
+const (
+	reasonPlanUnsupported   = "PlanUnsupported"
+	reasonWorkspaceDeleting = "WorkspaceDeleting"
+)
+
+type RunHelper struct {
+	client    client.Client
+	scheme    *runtime.Scheme
+	tfclient  *TerraformCloudClient
+	reqLogger logr.Logger
+	recorder  record.EventRecorder
+	options   Options
+}
+
+func NewRunHelper(mgr manager.Manager, options Options) reconcile.Reconciler {
+	tfclient := &TerraformCloudClient{}
+	err := tfclient.GetClient(os.Getenv("TF_URL"))
+	if err != nil {
+		log.Error(err, "could not create Terraform Cloud or Enterprise client")
+		os.Exit(1)
+	}
+	return &RunHelper{
+		client:    mgr.GetClient(),
+		scheme:    mgr.GetScheme(),
+		tfclient:  tfclient,
+		reqLogger: log,
+		recorder:  mgr.GetEventRecorderFor("run"),
+		options:   options,
+	}
+}
+
+// runObjectMessage is the message of the run created for a Run object, its UID tells it apart
+// from the runs of a deleted Run with the same name
+func runObjectMessage(instance *appv1alpha1.Run) string {
+	message := fmt.Sprintf("%s, Run %s (%s)", TerraformOperator, instance.Name, instance.UID)
+	if instance.Spec.Message != "" {
+		message = fmt.Sprintf("%s: %s", message, instance.Spec.Message)
+	}
+	return message
+}
+
+// gatedOperation reports whether runs of the operation wait for the apply mode and cost policy of their Workspace
+func gatedOperation(operation appv1alpha1.RunOperation) bool {
+	return operation == appv1alpha1.RunOperationApply || operation == appv1alpha1.RunOperationDestroy
+}
+
+// operationAutoApply reports whether the run of an operation is applied without waiting for confirmation
+func operationAutoApply(workspace *appv1alpha1.Workspace, operation appv1alpha1.RunOperation) bool {
+	return !gatedOperation(operation) || workspaceAutoApply(workspace)
+}
+
+// plannable reports whether the operator uploads the configuration of the Workspace, which a
+// speculative plan needs
+func plannable(workspace *appv1alpha1.Workspace) bool {
+	return workspace.Spec.VCS == nil && generatesConfiguration(workspace)
+}
+
+func setRunObjectCondition(instance *appv1alpha1.Run, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: instance.Generation,
+	})
+}
+
+// runCompletion maps the status of a finished run to the condition it sets on the Run,
+// done is false while the run is in progress
+func runCompletion(runID, status string) (conditionType, reason, message string, done bool) {
+	switch tfc.RunStatus(status) {
+	case tfc.RunApplied, tfc.RunPlannedAndFinished:
+		return appv1alpha1.RunConditionComplete, reasonRunCompleted, fmt.Sprintf("Run %s is %s", runID, status), true
+	case tfc.RunErrored:
+		return appv1alpha1.RunConditionFailed, reasonRunErrored, fmt.Sprintf("Run %s errored", runID), true
+	case tfc.RunCanceled:
+		return appv1alpha1.RunConditionFailed, reasonRunCanceled, fmt.Sprintf("Run %s was canceled", runID), true
+	case tfc.RunDiscarded:
+		return appv1alpha1.RunConditionFailed, reasonRunDiscarded, fmt.Sprintf("Run %s was discarded", runID), true
+	}
+	return "", "", "", false
+}
+
+// ttlRemaining returns how long a finished Run is kept before it is deleted, and false when it is kept forever
+func ttlRemaining(instance *appv1alpha1.Run, now time.Time) (time.Duration, bool) {
+	if instance.Spec.TTLSecondsAfterFinished == nil || instance.Status.CompletionTime == nil {
+		return 0, false
+	}
+	ttl := time.Duration(*instance.Spec.TTLSecondsAfterFinished) * time.Second
+	return instance.Status.CompletionTime.Add(ttl).Sub(now), true
+}
+
+// Reconcile creates the Terraform Cloud run of a Run object and mirrors its lifecycle into the Run status
+func (r *RunHelper) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	instance := &appv1alpha1.Run{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if instance.GetDeletionTimestamp() != nil {
+		return reconcile.Result{}, nil
+	}
+
+	if instance.Status.CompletionTime != nil {
+		return r.expireRun(instance)
+	}
+	if instance.Status.RunID == "" {
+		return r.createRun(instance)
+	}
+	return r.syncRun(instance)
+}
+
+// createRun queues the run in the workspace of the referenced Workspace once that workspace exists
+func (r *RunHelper) createRun(instance *appv1alpha1.Run) (reconcile.Result, error) {
+	workspace := &appv1alpha1.Workspace{}
+	key := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.WorkspaceRef.Name}
+	if err := r.client.Get(context.TODO(), key, workspace); err != nil {
+		if errors.IsNotFound(err) {
+			r.recorder.Event(instance, corev1.EventTypeWarning, "RunEvent",
+				fmt.Sprintf("Waiting for Workspace %s to exist", key.Name))
+			return reconcile.Result{RequeueAfter: requeueInterval}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if workspace.GetDeletionTimestamp() != nil {
+		return reconcile.Result{}, r.finishRun(instance, appv1alpha1.RunConditionFailed, reasonWorkspaceDeleting,
+			fmt.Sprintf("Workspace %s is being deleted", key.Name))
+	}
+	mode, err := readMaintenance(r.options.MaintenanceReader, r.options.MaintenanceConfigMap)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if suspended, _, message := suspension(workspace, mode); suspended {
+		r.recorder.Event(instance, corev1.EventTypeNormal, "RunEvent",
+			fmt.Sprintf("Waiting for Workspace %s to be resumed: %s", key.Name, message))
+		return reconcile.Result{RequeueAfter: requeueInterval}, nil
+	}
+	if instance.Spec.Operation == appv1alpha1.RunOperationPlan && !plannable(workspace) {
+		return reconcile.Result{}, r.finishRun(instance, appv1alpha1.RunConditionFailed, reasonPlanUnsupported,
+			fmt.Sprintf("Workspace %s has no configuration uploaded by the operator to plan speculatively", key.Name))
+	}
+	if workspace.Status.WorkspaceID == "" {
+		r.reqLogger.Info("Waiting for workspace to be created", "Run", instance.Name, "Workspace", key.Name)
+		return reconcile.Result{RequeueAfter: runPollInterval}, nil
+	}
+
+	// Runs are garbage collected with their Workspace
+	if !hasOwner(instance, workspace) {
+		if err := controllerutil.SetOwnerReference(workspace, instance, r.scheme); err != nil {
+			return reconcile.Result{}, err
+		}
+		if err := r.client.Update(context.TODO(), instance); err != nil {
+			r.reqLogger.Error(err, "Failed to set Run owner")
+			return reconcile.Result{}, err
+		}
+	}
+
+	// The workspace ID is recorded before the run is created, so a reconcile that created the run
+	// but could not record it finds the run instead of creating a second one
+	message := runObjectMessage(instance)
+	if instance.Status.WorkspaceID == "" {
+		instance.Status.WorkspaceID = workspace.Status.WorkspaceID
+		if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+			r.reqLogger.Error(err, "Failed to update Run status")
+			return reconcile.Result{}, err
+		}
+	} else {
+		run, err := r.tfclient.FindRun(instance.Status.WorkspaceID, message)
+		if err != nil {
+			r.reqLogger.Error(err, "Could not list runs", "Run", instance.Name, "WorkspaceID", instance.Status.WorkspaceID)
+			return reconcile.Result{}, err
+		}
+		if run != nil {
+			return r.recordCreatedRun(instance, workspace, run)
+		}
+	}
+
+	var configVersion *tfc.ConfigurationVersion
+	if instance.Spec.Operation == appv1alpha1.RunOperationPlan {
+		configVersion, err = r.speculativeConfiguration(instance, workspace)
+		if err != nil {
+			r.reqLogger.Error(err, "Could not upload configuration", "Run", instance.Name)
+			return reconcile.Result{}, err
+		}
+		if configVersion == nil {
+			r.reqLogger.Info("Waiting for configuration upload", "Run", instance.Name, "ConfigVersionID", instance.Status.ConfigVersionID)
+			return reconcile.Result{RequeueAfter: runPollInterval}, nil
+		}
+	}
+
+	autoApply := operationAutoApply(workspace, instance.Spec.Operation)
+	run, err := r.tfclient.CreateOperationRun(instance.Status.WorkspaceID, instance.Spec.Operation, message, autoApply, configVersion)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not create run", "Run", instance.Name, "WorkspaceID", instance.Status.WorkspaceID)
+		r.recorder.Event(instance, corev1.EventTypeWarning, "RunEvent", fmt.Sprintf("Could not create run: %s", err))
+		return reconcile.Result{}, err
+	}
+	return r.recordCreatedRun(instance, workspace, run)
+}
+
+// recordCreatedRun binds the Run to the run created for it
+func (r *RunHelper) recordCreatedRun(instance *appv1alpha1.Run, workspace *appv1alpha1.Workspace, run *tfc.Run) (reconcile.Result, error) {
+	now := metav1.Now()
+	instance.Status.RunID = run.ID
+	instance.Status.RunStatus = string(run.Status)
+	instance.Status.ConfigVersionID = ""
+	instance.Status.URL = runURL(r.tfclient.Address, workspace.Spec.Organization, workspace.Status.WorkspaceName, run.ID)
+	instance.Status.StartTime = &now
+	if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+		r.reqLogger.Error(err, "Failed to update Run status")
+		return reconcile.Result{}, err
+	}
+	r.recorder.Event(instance, corev1.EventTypeNormal, "RunEvent",
+		fmt.Sprintf("Created %s run %s in workspace %s", instance.Spec.Operation, run.ID, instance.Status.WorkspaceID))
+	r.reqLogger.Info("Created run", "Run", instance.Name, "Operation", instance.Spec.Operation,
+		"WorkspaceID", instance.Status.WorkspaceID, "RunID", run.ID)
+	return reconcile.Result{RequeueAfter: runPollInterval}, nil
+}
+
+// speculativeConfiguration uploads the configuration of the Workspace to a speculative configuration
+// version, so a Plan run neither locks the workspace nor waits in its run queue. It returns nil until
+// Terraform Cloud processed the upload.
+func (r *RunHelper) speculativeConfiguration(instance *appv1alpha1.Run, workspace *appv1alpha1.Workspace) (*tfc.ConfigurationVersion, error) {
+	if instance.Status.ConfigVersionID == "" {
+		cfgMap := &corev1.ConfigMap{}
+		key := types.NamespacedName{Name: workspace.Name, Namespace: workspace.Namespace}
+		if err := r.client.Get(context.TODO(), key, cfgMap); err != nil {
+			return nil, err
+		}
+		configVersion, err := r.tfclient.CreateSpeculativeConfigurationVersion(instance.Status.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.tfclient.uploadConfiguration(configVersion.UploadURL, configurationFiles(cfgMap.Data)); err != nil {
+			return nil, err
+		}
+		instance.Status.ConfigVersionID = configVersion.ID
+		if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+			r.reqLogger.Error(err, "Failed to update Run status")
+			return nil, err
+		}
+	}
+
+	return r.tfclient.UploadedConfigurationVersion(instance.Status.ConfigVersionID)
+}
+
+func hasOwner(instance *appv1alpha1.Run, workspace *appv1alpha1.Workspace) bool {
+	for _, owner := range instance.GetOwnerReferences() {
+		if owner.UID == workspace.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// syncRun mirrors the status of the run, and confirms Apply and Destroy runs the way their Workspace would
+func (r *RunHelper) syncRun(instance *appv1alpha1.Run) (reconcile.Result, error) {
+	runID := instance.Status.RunID
+	status, err := r.tfclient.CheckRun(runID)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not get run", "RunID", runID)
+		return reconcile.Result{}, err
+	}
+
+	if conditionType, reason, message, done := runCompletion(runID, status); done {
+		instance.Status.RunStatus = status
+		return r.expireAfter(instance, r.finishRun(instance, conditionType, reason, message))
+	}
+
+	if instance.Status.RunStatus != status {
+		instance.Status.RunStatus = status
+		if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+			r.reqLogger.Error(err, "Failed to update Run status")
+			return reconcile.Result{}, err
+		}
+	}
+
+	if tfc.RunStatus(status) == tfc.RunPolicySoftFailed {
+		// The run would hold the run queue of the Workspace until someone overrides its policies
+		if err := r.discardPolicySoftFailedRun(instance); err != nil {
+			return reconcile.Result{}, err
+		}
+		return r.expireAfter(instance, r.finishRun(instance, appv1alpha1.RunConditionFailed, reasonPolicySoftFailed,
+			fmt.Sprintf("Run %s failed soft-mandatory policies and was discarded", runID)))
+	}
+
+	if gatedOperation(instance.Spec.Operation) && isConfirmable(status) {
+		workspace := &appv1alpha1.Workspace{}
+		key := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.WorkspaceRef.Name}
+		if err := r.client.Get(context.TODO(), key, workspace); err != nil {
+			// The Run is garbage collected with its Workspace
+			return reconcile.Result{}, client.IgnoreNotFound(err)
+		}
+		if err := r.confirmRun(instance, workspace); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return reconcile.Result{RequeueAfter: runPollInterval}, nil
+}
+
+// finishRun records the outcome of the run and the resource changes it planned or applied
+func (r *RunHelper) finishRun(instance *appv1alpha1.Run, conditionType, reason, message string) error {
+	if instance.Status.RunID != "" {
+		applied := tfc.RunStatus(instance.Status.RunStatus) == tfc.RunApplied
+		changes, err := r.tfclient.GetRunChanges(instance.Status.RunID, applied)
+		if err != nil {
+			r.reqLogger.Info("Could not get run changes", "RunID", instance.Status.RunID, "Error", err.Error())
+		} else {
+			instance.Status.Changes = changes
+			message = fmt.Sprintf("%s (%s)", message, changes.Summary)
+		}
+	}
+
+	now := metav1.Now()
+	instance.Status.CompletionTime = &now
+	setRunObjectCondition(instance, conditionType, metav1.ConditionTrue, reason, message)
+	if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+		r.reqLogger.Error(err, "Failed to update Run status")
+		return err
+	}
+
+	eventType := corev1.EventTypeNormal
+	if conditionType == appv1alpha1.RunConditionFailed {
+		eventType = corev1.EventTypeWarning
+	}
+	r.recorder.Event(instance, eventType, "RunEvent", message)
+	r.reqLogger.Info("Run finished", "Run", instance.Name, "RunID", instance.Status.RunID, "Reason", reason)
+	return nil
+}
+
+func (r *RunHelper) expireAfter(instance *appv1alpha1.Run, err error) (reconcile.Result, error) {
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	return r.expireRun(instance)
+}
+
+// expireRun deletes a finished Run once its TTL passed
+func (r *RunHelper) expireRun(instance *appv1alpha1.Run) (reconcile.Result, error) {
+	remaining, ok := ttlRemaining(instance, time.Now())
+	if !ok {
+		return reconcile.Result{}, nil
+	}
+	if remaining > 0 {
+		return reconcile.Result{RequeueAfter: remaining}, nil
+	}
+	if err := r.client.Delete(context.TODO(), instance); err != nil && !errors.IsNotFound(err) {
+		r.reqLogger.Error(err, "Failed to delete expired Run")
+		return reconcile.Result{}, err
+	}
+	r.reqLogger.Info("Deleted expired Run", "Run", instance.Name, "RunID", instance.Status.RunID)
+	return reconcile.Result{}, nil
+}
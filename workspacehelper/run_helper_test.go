@@ -0,0 +1,219 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRunObjectMessage(t *testing.T) {
+	run := &v1alpha1.Run{ObjectMeta: metav1.ObjectMeta{Name: "nightly", UID: "uid-1"}}
+	assert.Equal(t, "terraform-k8s, Run nightly (uid-1)", runObjectMessage(run))
+
+	run.Spec.Message = "rotate credentials"
+	assert.Equal(t, "terraform-k8s, Run nightly (uid-1): rotate credentials", runObjectMessage(run))
+}
+
+func TestOperationAutoApply(t *testing.T) {
+	manual := &v1alpha1.Workspace{Spec: v1alpha1.WorkspaceSpec{ApplyMode: v1alpha1.ApplyModeManual}}
+	budgeted := &v1alpha1.Workspace{Spec: v1alpha1.WorkspaceSpec{CostPolicy: &v1alpha1.CostPolicy{MaxMonthlyDelta: "10"}}}
+	auto := &v1alpha1.Workspace{}
+
+	for _, operation := range []v1alpha1.RunOperation{v1alpha1.RunOperationApply, v1alpha1.RunOperationDestroy} {
+		assert.True(t, operationAutoApply(auto, operation), operation)
+		assert.False(t, operationAutoApply(manual, operation), operation)
+		assert.False(t, operationAutoApply(budgeted, operation), operation)
+	}
+	assert.True(t, operationAutoApply(manual, v1alpha1.RunOperationRefreshOnly))
+	assert.True(t, operationAutoApply(budgeted, v1alpha1.RunOperationRefreshOnly))
+}
+
+func TestPlannable(t *testing.T) {
+	assert.False(t, plannable(&v1alpha1.Workspace{}))
+	assert.True(t, plannable(&v1alpha1.Workspace{Spec: v1alpha1.WorkspaceSpec{Module: &v1alpha1.Module{Source: "./module"}}}))
+	assert.False(t, plannable(&v1alpha1.Workspace{Spec: v1alpha1.WorkspaceSpec{
+		Module: &v1alpha1.Module{Source: "./module"},
+		VCS:    &v1alpha1.VCS{RepoIdentifier: "org/repo"},
+	}}))
+}
+
+func TestConfirmRun(t *testing.T) {
+	runResponse := `{"data": {"id": "run-1", "type": "runs",
+		"attributes": {"status": "cost_estimated", "actions": {"is-confirmable": %t, "is-discardable": true}},
+		"relationships": {"cost-estimate": {"data": {"id": "ce-1", "type": "cost-estimates"}}}},
+		"included": [{"id": "ce-1", "type": "cost-estimates",
+			"attributes": {"status": "finished", "delta-monthly-cost": "%s"}}]}`
+	tests := []struct {
+		name        string
+		confirmable bool
+		delta       string
+		wantPost    string
+		wantEvent   string
+	}{
+		{name: "Not confirmable", delta: "5"},
+		{name: "Within budget", confirmable: true, delta: "5",
+			wantPost: "/api/v2/runs/run-1/actions/apply", wantEvent: "Applying run run-1"},
+		{name: "Over budget", confirmable: true, delta: "50",
+			wantPost: "/api/v2/runs/run-1/actions/discard", wantEvent: "Discarded run run-1, it is over the cost budget"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posts []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/vnd.api+json")
+				if r.Method == http.MethodPost {
+					posts = append(posts, r.URL.Path)
+					w.WriteHeader(http.StatusAccepted)
+					return
+				}
+				fmt.Fprintf(w, runResponse, tt.confirmable, tt.delta)
+			}))
+			defer srv.Close()
+			client, err := tfe.NewClient(&tfe.Config{
+				Address:    srv.URL,
+				Token:      "token1",
+				HTTPClient: srv.Client(),
+			})
+			assert.NoError(t, err)
+
+			recorder := record.NewFakeRecorder(10)
+			r := &RunHelper{
+				tfclient:  &TerraformCloudClient{Client: client},
+				reqLogger: log,
+				recorder:  recorder,
+			}
+			workspace := &v1alpha1.Workspace{
+				Spec: v1alpha1.WorkspaceSpec{CostPolicy: &v1alpha1.CostPolicy{MaxMonthlyDelta: "10"}},
+			}
+			run := &v1alpha1.Run{Spec: v1alpha1.RunSpec{Operation: v1alpha1.RunOperationApply}}
+			run.Status.RunID = "run-1"
+			assert.NoError(t, r.confirmRun(run, workspace))
+			if tt.wantPost == "" {
+				assert.Empty(t, posts)
+				return
+			}
+			assert.Equal(t, []string{tt.wantPost}, posts)
+			assert.Contains(t, <-recorder.Events, tt.wantEvent)
+		})
+	}
+}
+
+func TestDiscardPolicySoftFailedRun(t *testing.T) {
+	var posts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			posts = append(posts, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+	client, err := tfe.NewClient(&tfe.Config{
+		Address:    srv.URL,
+		Token:      "token1",
+		HTTPClient: srv.Client(),
+	})
+	assert.NoError(t, err)
+
+	r := &RunHelper{tfclient: &TerraformCloudClient{Client: client}, reqLogger: log}
+	run := &v1alpha1.Run{Spec: v1alpha1.RunSpec{Operation: v1alpha1.RunOperationApply}}
+	run.Status.RunID = "run-1"
+	run.Status.RunStatus = "policy_soft_failed"
+	assert.NoError(t, r.discardPolicySoftFailedRun(run))
+	assert.Equal(t, []string{"/api/v2/runs/run-1/actions/discard"}, posts)
+	assert.Equal(t, "discarded", run.Status.RunStatus)
+}
+
+func TestRunCompletion(t *testing.T) {
+	tests := []struct {
+		status        string
+		done          bool
+		conditionType string
+		reason        string
+	}{
+		{"planning", false, "", ""},
+		{"planned", false, "", ""},
+		{"applied", true, v1alpha1.RunConditionComplete, reasonRunCompleted},
+		{"planned_and_finished", true, v1alpha1.RunConditionComplete, reasonRunCompleted},
+		{"errored", true, v1alpha1.RunConditionFailed, reasonRunErrored},
+		{"canceled", true, v1alpha1.RunConditionFailed, reasonRunCanceled},
+		{"discarded", true, v1alpha1.RunConditionFailed, reasonRunDiscarded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			conditionType, reason, _, done := runCompletion("run-123", tt.status)
+			assert.Equal(t, tt.done, done)
+			assert.Equal(t, tt.conditionType, conditionType)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestTTLRemaining(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	run := &v1alpha1.Run{}
+	_, ok := ttlRemaining(run, now)
+	assert.False(t, ok, "kept forever without a TTL")
+
+	ttl := int32(600)
+	run.Spec.TTLSecondsAfterFinished = &ttl
+	_, ok = ttlRemaining(run, now)
+	assert.False(t, ok, "not finished yet")
+
+	completion := metav1.NewTime(now.Add(-4 * time.Minute))
+	run.Status.CompletionTime = &completion
+	remaining, ok := ttlRemaining(run, now)
+	assert.True(t, ok)
+	assert.Equal(t, 6*time.Minute, remaining)
+
+	completion = metav1.NewTime(now.Add(-time.Hour))
+	remaining, _ = ttlRemaining(run, now)
+	assert.True(t, remaining <= 0)
+}
+
+func TestRunObjectRunRecorded(t *testing.T) {
+	workspace := &v1alpha1.Workspace{}
+	workspace.Status.RunHistory = []v1alpha1.RunHistoryEntry{
+		{ID: "run-pending", Status: "planning", Source: v1alpha1.RunSourceRunObject},
+		{ID: "run-applied", Status: "applied", Source: v1alpha1.RunSourceRunObject},
+		{ID: "run-ui", Status: "applied", Source: v1alpha1.RunSourceUI},
+	}
+	assert.False(t, runObjectRunRecorded(workspace, "run-pending"))
+	assert.True(t, runObjectRunRecorded(workspace, "run-applied"))
+	assert.False(t, runObjectRunRecorded(workspace, "run-ui"))
+	assert.False(t, runObjectRunRecorded(workspace, "run-unknown"))
+}
+
+func TestRunObjectRunState(t *testing.T) {
+	workspace := &v1alpha1.Workspace{ObjectMeta: metav1.ObjectMeta{Name: "greetings"}}
+	runs := []v1alpha1.Run{
+		{Spec: v1alpha1.RunSpec{WorkspaceRef: corev1.LocalObjectReference{Name: "greetings"}},
+			Status: v1alpha1.RunStatus{WorkspaceID: "ws-1", RunID: "run-1"}},
+		{Spec: v1alpha1.RunSpec{WorkspaceRef: corev1.LocalObjectReference{Name: "other"}},
+			Status: v1alpha1.RunStatus{WorkspaceID: "ws-2", RunID: "run-2"}},
+	}
+	found, creating := runObjectRunState(workspace, runs, "run-1")
+	assert.True(t, found)
+	assert.False(t, creating)
+
+	found, creating = runObjectRunState(workspace, runs, "run-2")
+	assert.False(t, found, "run of another Workspace")
+	assert.False(t, creating)
+
+	runs = append(runs, v1alpha1.Run{Spec: v1alpha1.RunSpec{WorkspaceRef: corev1.LocalObjectReference{Name: "greetings"}},
+		Status: v1alpha1.RunStatus{WorkspaceID: "ws-1"}})
+	found, creating = runObjectRunState(workspace, runs, "run-3")
+	assert.False(t, found)
+	assert.True(t, creating, "a Run is creating its run")
+}
@@ -5,8 +5,13 @@ package workspacehelper
 
 import (
 	"bytes"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
 	"text/template"
 
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
 )
 
@@ -17,27 +22,112 @@ const (
 	TerraformOperator = "terraform-k8s"
 )
 
-// CreateTerraformTemplate creates a template for the Terraform configuration
-func CreateTerraformTemplate(workspace *v1alpha1.Workspace) ([]byte, error) {
-	tfTemplate, err := template.New("main.tf").Parse(`terraform {
+// cloudBlockVersion is the first Terraform version supporting the cloud block
+var cloudBlockVersion = version.Must(version.NewVersion("1.1.0"))
+
+// terraformTemplate is the data of the generated configuration
+type terraformTemplate struct {
+	*v1alpha1.Workspace
+	// WorkspaceName is the name of the Terraform Cloud workspace
+	WorkspaceName string
+	// Hostname of Terraform Enterprise, empty for Terraform Cloud
+	Hostname string
+	// CloudBlock selects the cloud block over the deprecated remote backend
+	CloudBlock bool
+}
+
+// usesCloudBlock reports whether the Terraform version of the workspace supports the cloud block.
+// Workspaces without a version use the latest one.
+func usesCloudBlock(terraformVersion string) bool {
+	v, err := version.NewVersion(terraformVersion)
+	if err != nil {
+		return true
+	}
+	return !v.LessThan(cloudBlockVersion)
+}
+
+// terraformHostname returns the hostname of a Terraform Cloud or Enterprise address such as TF_URL
+func terraformHostname(address string) string {
+	u, err := url.Parse(address)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// CreateTerraformTemplate creates a template for the Terraform configuration, storing its state
+// in the workspace named workspaceName on the given hostname
+func CreateTerraformTemplate(workspace *v1alpha1.Workspace, workspaceName, hostname string) ([]byte, error) {
+	funcs := template.FuncMap{"requiredProviders": requiredProviders}
+	tfTemplate, err := template.New("main.tf").Funcs(funcs).Parse(`terraform {
+		{{- if .Spec.RequiredVersion}}
+		required_version = "{{.Spec.RequiredVersion}}"
+		{{- end}}
+		{{- with requiredProviders .Spec.Providers}}
+		required_providers {
+			{{- range .}}
+			{{.Name}} = {
+				{{- if .Source}}
+				source = "{{.Source}}"
+				{{- end}}
+				{{- if .Version}}
+				version = "{{.Version}}"
+				{{- end}}
+			}
+			{{- end}}
+		}
+		{{- end}}
+		{{- if .CloudBlock}}
+		cloud {
+			{{- if .Hostname}}
+			hostname = "{{.Hostname}}"
+			{{- end}}
+			organization = "{{.Spec.Organization}}"
+	
+			workspaces {
+				name = "{{.WorkspaceName}}"
+			}
+		}
+		{{- else}}
 		backend "remote" {
+			{{- if .Hostname}}
+			hostname = "{{.Hostname}}"
+			{{- end}}
 			organization = "{{.Spec.Organization}}"
 	
 			workspaces {
-				name = "{{.ObjectMeta.Namespace}}-{{.ObjectMeta.Name}}"
+				name = "{{.WorkspaceName}}"
 			}
 		}
+		{{- end}}
 	}
 	{{- range .Spec.Variables}}
 	{{- if not .EnvironmentVariable }}
 	variable "{{.Key}}" {}
 	{{- end}}
 	{{- end}}
+	{{- range .Spec.Providers}}
+	provider "{{.Name}}" {
+		{{- if .Alias}}
+		alias = "{{.Alias}}"
+		{{- end}}
+		{{- range $key, $value := .Config}}
+		{{$key}} = {{$value}}
+		{{- end}}
+	}
+	{{- end}}
+	{{- if or .Spec.Module .Spec.Modules}}
 	{{- range .Spec.Outputs}}
 	output "{{.Key}}" {
+		{{- if $.Spec.Modules}}
+		value = module.{{.ModuleOutputName}}
+		{{- else}}
 		value = module.operator.{{.ModuleOutputName}}
+		{{- end}}
 	}
 	{{- end}}
+	{{- end}}
+	{{- if .Spec.Module}}
 	module "operator" {
 		source = "{{.Spec.Module.Source}}"
 		{{- if .Spec.Module.Version }}
@@ -48,13 +138,166 @@ func CreateTerraformTemplate(workspace *v1alpha1.Workspace) ([]byte, error) {
 		{{.Key}} = var.{{.Key}}
 		{{- end}}
 		{{- end}}
-	}`)
+	}
+	{{- end}}
+	{{- range .Spec.Modules}}
+	module "{{.Name}}" {
+		source = "{{.Source}}"
+		{{- if .Version }}
+		version = "{{.Version}}"
+		{{- end}}
+		{{- range .Inputs}}
+		{{- if .Variable}}
+		{{.Name}} = var.{{.Variable}}
+		{{- else}}
+		{{.Name}} = module.{{.ModuleOutput}}
+		{{- end}}
+		{{- end}}
+		{{- with .Providers}}
+		providers = {
+			{{- range $key, $value := .}}
+			{{$key}} = {{$value}}
+			{{- end}}
+		}
+		{{- end}}
+	}
+	{{- end}}`)
 	if err != nil {
 		return nil, err
 	}
 	var tpl bytes.Buffer
-	if err := tfTemplate.Execute(&tpl, workspace); err != nil {
+	data := terraformTemplate{
+		Workspace:     workspace,
+		WorkspaceName: workspaceName,
+		Hostname:      hostname,
+		CloudBlock:    usesCloudBlock(workspace.Spec.TerraformVersion),
+	}
+	if err := tfTemplate.Execute(&tpl, data); err != nil {
 		return nil, err
 	}
 	return tpl.Bytes(), nil
 }
+
+// generatesTemplate reports whether the Workspace needs the generated main.tf
+func generatesTemplate(workspace *v1alpha1.Workspace) bool {
+	spec := workspace.Spec
+	return spec.Module != nil || len(spec.Modules) > 0 || len(spec.Providers) > 0 || spec.RequiredVersion != ""
+}
+
+// requiredProviders returns the providers of the required_providers block, once per name and sorted by name.
+// Source and version are taken from the first entry of a provider that sets them.
+func requiredProviders(providers []v1alpha1.Provider) []v1alpha1.Provider {
+	byName := map[string]*v1alpha1.Provider{}
+	for _, provider := range providers {
+		required, ok := byName[provider.Name]
+		if !ok {
+			required = &v1alpha1.Provider{Name: provider.Name}
+			byName[provider.Name] = required
+		}
+		if required.Source == "" {
+			required.Source = provider.Source
+		}
+		if required.Version == "" {
+			required.Version = provider.Version
+		}
+	}
+
+	required := []v1alpha1.Provider{}
+	for _, provider := range byName {
+		if provider.Source != "" || provider.Version != "" {
+			required = append(required, *provider)
+		}
+	}
+	sort.Slice(required, func(i, j int) bool { return required[i].Name < required[j].Name })
+	return required
+}
+
+// validateProviders checks that every provider configuration is unique and that modules
+// are only passed providers of the Workspace
+func validateProviders(workspace *v1alpha1.Workspace) error {
+	configurations := map[string]bool{}
+	for _, provider := range workspace.Spec.Providers {
+		if provider.Name == "" {
+			return fmt.Errorf("providers need a name")
+		}
+		key := provider.Name
+		if provider.Alias != "" {
+			key = fmt.Sprintf("%s.%s", provider.Name, provider.Alias)
+		}
+		if configurations[key] {
+			return fmt.Errorf("provider %s is configured more than once", key)
+		}
+		configurations[key] = true
+	}
+
+	for _, module := range workspace.Spec.Modules {
+		for name, provider := range module.Providers {
+			if !configurations[provider] {
+				return fmt.Errorf("module %s is passed provider %s for %s, which is not a provider of the Workspace",
+					module.Name, provider, name)
+			}
+		}
+	}
+	return nil
+}
+
+// splitModuleOutput splits a <module>.<output> reference
+func splitModuleOutput(reference string) (string, string, bool) {
+	parts := strings.SplitN(reference, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// validateModules checks that the inputs and outputs of the named modules reference
+// variables of the Workspace and other modules that exist
+func validateModules(workspace *v1alpha1.Workspace) error {
+	if len(workspace.Spec.Modules) == 0 {
+		return nil
+	}
+	if workspace.Spec.Module != nil {
+		return fmt.Errorf("module and modules cannot both be set")
+	}
+
+	variables := map[string]bool{}
+	for _, variable := range workspace.Spec.Variables {
+		if !variable.EnvironmentVariable {
+			variables[variable.Key] = true
+		}
+	}
+	modules := map[string]bool{}
+	for _, module := range workspace.Spec.Modules {
+		if modules[module.Name] {
+			return fmt.Errorf("module %s is defined more than once", module.Name)
+		}
+		modules[module.Name] = true
+	}
+
+	for _, module := range workspace.Spec.Modules {
+		for _, input := range module.Inputs {
+			switch {
+			case (input.Variable == "") == (input.ModuleOutput == ""):
+				return fmt.Errorf("input %s of module %s needs either a variable or a module output", input.Name, module.Name)
+			case input.Variable != "" && !variables[input.Variable]:
+				return fmt.Errorf("input %s of module %s uses variable %s, which is not a Terraform variable of the Workspace",
+					input.Name, module.Name, input.Variable)
+			case input.ModuleOutput != "":
+				source, _, ok := splitModuleOutput(input.ModuleOutput)
+				if !ok || !modules[source] || source == module.Name {
+					return fmt.Errorf("input %s of module %s uses %s, which is not an output of another module",
+						input.Name, module.Name, input.ModuleOutput)
+				}
+			}
+		}
+	}
+
+	for _, output := range workspace.Spec.Outputs {
+		source, _, ok := splitModuleOutput(output.ModuleOutputName)
+		if !ok || !modules[source] {
+			return fmt.Errorf("output %s uses %s, which is not an output of a module in the form <module>.<output>",
+				output.Key, output.ModuleOutputName)
+		}
+	}
+	return nil
+}
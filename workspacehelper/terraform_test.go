@@ -36,7 +36,8 @@ func TestShouldCreateTerraformWithVariables(t *testing.T) {
 			Namespace: "prod",
 		},
 		Spec: v1alpha1.WorkspaceSpec{
-			Organization: "world",
+			Organization:     "world",
+			TerraformVersion: "1.0.11",
 			Module: &v1alpha1.Module{
 				Source:  "my_source",
 				Version: "0.3.2",
@@ -57,7 +58,7 @@ func TestShouldCreateTerraformWithVariables(t *testing.T) {
 			},
 		},
 	}
-	terraformFile, err := CreateTerraformTemplate(workspace)
+	terraformFile, err := CreateTerraformTemplate(workspace, "prod-hello", "")
 	assert.Nil(t, err)
 	assert.Equal(t, expectedFile, string(terraformFile))
 }
@@ -83,14 +84,15 @@ func TestShouldCreateTerraformWithNoVariables(t *testing.T) {
 			Namespace: "prod",
 		},
 		Spec: v1alpha1.WorkspaceSpec{
-			Organization: "world",
+			Organization:     "world",
+			TerraformVersion: "1.0.11",
 			Module: &v1alpha1.Module{
 				Source:  "my_source",
 				Version: "0.3.2",
 			},
 		},
 	}
-	terraformFile, err := CreateTerraformTemplate(workspace)
+	terraformFile, err := CreateTerraformTemplate(workspace, "prod-hello", "")
 	assert.Nil(t, err)
 	assert.Equal(t, expectedFile, string(terraformFile))
 }
@@ -116,7 +118,8 @@ func TestShouldCreateTerraformWithNoEnvironmentVariables(t *testing.T) {
 			Namespace: "prod",
 		},
 		Spec: v1alpha1.WorkspaceSpec{
-			Organization: "world",
+			Organization:     "world",
+			TerraformVersion: "1.0.11",
 			Module: &v1alpha1.Module{
 				Source:  "my_source",
 				Version: "0.3.2",
@@ -131,7 +134,7 @@ func TestShouldCreateTerraformWithNoEnvironmentVariables(t *testing.T) {
 			},
 		},
 	}
-	terraformFile, err := CreateTerraformTemplate(workspace)
+	terraformFile, err := CreateTerraformTemplate(workspace, "prod-hello", "")
 	assert.Nil(t, err)
 	assert.Equal(t, expectedFile, string(terraformFile))
 }
@@ -163,7 +166,8 @@ func TestShouldCreateTerraformWithOutputs(t *testing.T) {
 			Namespace: "prod",
 		},
 		Spec: v1alpha1.WorkspaceSpec{
-			Organization: "world",
+			Organization:     "world",
+			TerraformVersion: "1.0.11",
 			Module: &v1alpha1.Module{
 				Source:  "my_source",
 				Version: "0.3.2",
@@ -180,7 +184,7 @@ func TestShouldCreateTerraformWithOutputs(t *testing.T) {
 			},
 		},
 	}
-	terraformFile, err := CreateTerraformTemplate(workspace)
+	terraformFile, err := CreateTerraformTemplate(workspace, "prod-hello", "")
 	assert.Nil(t, err)
 	assert.Equal(t, expectedFile, string(terraformFile))
 }
@@ -205,13 +209,267 @@ func TestShouldCreateTerraformWithNoModuleVersion(t *testing.T) {
 			Namespace: "prod",
 		},
 		Spec: v1alpha1.WorkspaceSpec{
-			Organization: "world",
+			Organization:     "world",
+			TerraformVersion: "1.0.11",
 			Module: &v1alpha1.Module{
 				Source: "my_source",
 			},
 		},
 	}
-	terraformFile, err := CreateTerraformTemplate(workspace)
+	terraformFile, err := CreateTerraformTemplate(workspace, "prod-hello", "")
 	assert.Nil(t, err)
 	assert.Equal(t, expectedFile, string(terraformFile))
 }
+
+func TestShouldCreateTerraformWithModules(t *testing.T) {
+	expectedFile := `terraform {
+		backend "remote" {
+			organization = "world"
+	
+			workspaces {
+				name = "prod-hello"
+			}
+		}
+	}
+	variable "cidr" {}
+	output "endpoint" {
+		value = module.database.endpoint
+	}
+	module "network" {
+		source = "terraform-aws-modules/vpc/aws"
+		version = "3.11.0"
+		cidr = var.cidr
+	}
+	module "database" {
+		source = "git::https://example.com/database.git"
+		subnet_ids = module.network.private_subnets
+	}`
+
+	workspace := &v1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hello",
+			Namespace: "prod",
+		},
+		Spec: v1alpha1.WorkspaceSpec{
+			Organization:     "world",
+			TerraformVersion: "1.0.11",
+			Modules: []v1alpha1.NamedModule{
+				{
+					Name:    "network",
+					Source:  "terraform-aws-modules/vpc/aws",
+					Version: "3.11.0",
+					Inputs:  []v1alpha1.ModuleInput{{Name: "cidr", Variable: "cidr"}},
+				},
+				{
+					Name:   "database",
+					Source: "git::https://example.com/database.git",
+					Inputs: []v1alpha1.ModuleInput{{Name: "subnet_ids", ModuleOutput: "network.private_subnets"}},
+				},
+			},
+			Variables: []*v1alpha1.Variable{
+				{
+					Key:   "cidr",
+					Value: "10.0.0.0/16",
+				},
+			},
+			Outputs: []*v1alpha1.OutputSpec{
+				{
+					Key:              "endpoint",
+					ModuleOutputName: "database.endpoint",
+				},
+			},
+		},
+	}
+	assert.Nil(t, validateModules(workspace))
+	terraformFile, err := CreateTerraformTemplate(workspace, "prod-hello", "")
+	assert.Nil(t, err)
+	assert.Equal(t, expectedFile, string(terraformFile))
+}
+
+func TestValidateModules(t *testing.T) {
+	valid := func() *v1alpha1.Workspace {
+		return &v1alpha1.Workspace{Spec: v1alpha1.WorkspaceSpec{
+			Modules: []v1alpha1.NamedModule{
+				{Name: "network", Source: "network", Inputs: []v1alpha1.ModuleInput{{Name: "cidr", Variable: "cidr"}}},
+				{Name: "database", Source: "database", Inputs: []v1alpha1.ModuleInput{{Name: "vpc_id", ModuleOutput: "network.vpc_id"}}},
+			},
+			Variables: []*v1alpha1.Variable{{Key: "cidr"}, {Key: "TF_LOG", EnvironmentVariable: true}},
+			Outputs:   []*v1alpha1.OutputSpec{{Key: "vpc", ModuleOutputName: "network.vpc_id"}},
+		}}
+	}
+	assert.Nil(t, validateModules(valid()))
+	assert.Nil(t, validateModules(&v1alpha1.Workspace{Spec: v1alpha1.WorkspaceSpec{Module: &v1alpha1.Module{Source: "hello"}}}))
+
+	tests := []struct {
+		name   string
+		modify func(*v1alpha1.Workspace)
+	}{
+		{"Module and modules", func(w *v1alpha1.Workspace) { w.Spec.Module = &v1alpha1.Module{Source: "hello"} }},
+		{"Duplicate module", func(w *v1alpha1.Workspace) { w.Spec.Modules[1].Name = "network" }},
+		{"Unknown variable", func(w *v1alpha1.Workspace) { w.Spec.Modules[0].Inputs[0].Variable = "region" }},
+		{"Environment variable", func(w *v1alpha1.Workspace) { w.Spec.Modules[0].Inputs[0].Variable = "TF_LOG" }},
+		{"Variable and module output", func(w *v1alpha1.Workspace) { w.Spec.Modules[1].Inputs[0].Variable = "cidr" }},
+		{"Own output", func(w *v1alpha1.Workspace) { w.Spec.Modules[1].Inputs[0].ModuleOutput = "database.id" }},
+		{"Unknown module output", func(w *v1alpha1.Workspace) { w.Spec.Modules[1].Inputs[0].ModuleOutput = "vpc.id" }},
+		{"Output without module", func(w *v1alpha1.Workspace) { w.Spec.Outputs[0].ModuleOutputName = "vpc_id" }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			workspace := valid()
+			test.modify(workspace)
+			assert.Error(t, validateModules(workspace))
+		})
+	}
+}
+
+func TestShouldCreateTerraformWithProviders(t *testing.T) {
+	expectedFile := `terraform {
+		required_version = ">= 1.0"
+		required_providers {
+			aws = {
+				source = "hashicorp/aws"
+				version = "~> 3.0"
+			}
+		}
+		backend "remote" {
+			organization = "world"
+	
+			workspaces {
+				name = "prod-hello"
+			}
+		}
+	}
+	variable "region" {}
+	provider "aws" {
+		region = var.region
+	}
+	provider "aws" {
+		alias = "east"
+		region = "us-east-1"
+	}
+	provider "random" {
+	}
+	module "replica" {
+		source = "my_source"
+		providers = {
+			aws = aws.east
+		}
+	}`
+
+	workspace := &v1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hello",
+			Namespace: "prod",
+		},
+		Spec: v1alpha1.WorkspaceSpec{
+			Organization:     "world",
+			TerraformVersion: "1.0.11",
+			RequiredVersion:  ">= 1.0",
+			Providers: []v1alpha1.Provider{
+				{Name: "aws", Config: map[string]string{"region": "var.region"}},
+				{Name: "aws", Alias: "east", Source: "hashicorp/aws", Version: "~> 3.0",
+					Config: map[string]string{"region": `"us-east-1"`}},
+				{Name: "random"},
+			},
+			Modules: []v1alpha1.NamedModule{
+				{Name: "replica", Source: "my_source", Providers: map[string]string{"aws": "aws.east"}},
+			},
+			Variables: []*v1alpha1.Variable{
+				{
+					Key:   "region",
+					Value: "us-west-2",
+				},
+			},
+		},
+	}
+	assert.Nil(t, validateProviders(workspace))
+	terraformFile, err := CreateTerraformTemplate(workspace, "prod-hello", "")
+	assert.Nil(t, err)
+	assert.Equal(t, expectedFile, string(terraformFile))
+	assert.Nil(t, validateConfiguration(map[string]string{mainFile: string(terraformFile)}))
+}
+
+func TestShouldCreateTerraformWithProvidersOnly(t *testing.T) {
+	workspace := &v1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hello",
+			Namespace: "prod",
+		},
+		Spec: v1alpha1.WorkspaceSpec{
+			Organization:  "world",
+			Providers:     []v1alpha1.Provider{{Name: "random", Source: "hashicorp/random"}},
+			Configuration: &v1alpha1.Configuration{Files: map[string]string{"pet.tf": `resource "random_pet" "name" {}`}},
+			Outputs:       []*v1alpha1.OutputSpec{{Key: "name", ModuleOutputName: "name"}},
+		},
+	}
+	assert.True(t, generatesTemplate(workspace))
+	terraformFile, err := CreateTerraformTemplate(workspace, "prod-hello", "")
+	assert.Nil(t, err)
+	assert.NotContains(t, string(terraformFile), "output", "outputs are declared in the files without a module")
+	assert.NotContains(t, string(terraformFile), "module")
+}
+
+func TestValidateProviders(t *testing.T) {
+	workspace := &v1alpha1.Workspace{Spec: v1alpha1.WorkspaceSpec{
+		Providers: []v1alpha1.Provider{{Name: "aws"}, {Name: "aws", Alias: "east"}},
+		Modules:   []v1alpha1.NamedModule{{Name: "replica", Providers: map[string]string{"aws": "aws.east"}}},
+	}}
+	assert.Nil(t, validateProviders(workspace))
+
+	workspace.Spec.Modules[0].Providers["aws"] = "aws.west"
+	assert.Error(t, validateProviders(workspace))
+
+	workspace.Spec.Modules = nil
+	workspace.Spec.Providers = append(workspace.Spec.Providers, v1alpha1.Provider{Name: "aws"})
+	assert.Error(t, validateProviders(workspace))
+}
+
+func TestShouldCreateTerraformWithCloudBlock(t *testing.T) {
+	expectedFile := `terraform {
+		cloud {
+			hostname = "tfe.example.com"
+			organization = "world"
+	
+			workspaces {
+				name = "hello"
+			}
+		}
+	}
+	module "operator" {
+		source = "my_source"
+	}`
+
+	workspace := &v1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hello",
+			Namespace: "prod",
+		},
+		Spec: v1alpha1.WorkspaceSpec{
+			Organization:        "world",
+			OmitNamespacePrefix: true,
+			Module: &v1alpha1.Module{
+				Source: "my_source",
+			},
+		},
+	}
+	name, err := workspaceName(workspace, Options{})
+	assert.Nil(t, err)
+	terraformFile, err := CreateTerraformTemplate(workspace, name, terraformHostname("https://tfe.example.com"))
+	assert.Nil(t, err)
+	assert.Equal(t, expectedFile, string(terraformFile))
+}
+
+func TestUsesCloudBlock(t *testing.T) {
+	assert.True(t, usesCloudBlock(""), "workspaces without a version use the latest one")
+	assert.True(t, usesCloudBlock("1.1.0"))
+	assert.True(t, usesCloudBlock("1.3.7"))
+	assert.False(t, usesCloudBlock("1.0.11"))
+	assert.False(t, usesCloudBlock("0.15.2"))
+	assert.False(t, usesCloudBlock("1.1.0-alpha20210811"))
+}
+
+func TestTerraformHostname(t *testing.T) {
+	assert.Equal(t, "", terraformHostname(""))
+	assert.Equal(t, "tfe.example.com", terraformHostname("https://tfe.example.com"))
+	assert.Equal(t, "tfe.example.com:8443", terraformHostname("https://tfe.example.com:8443/"))
+}
@@ -0,0 +1,124 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	tfc "github.com/hashicorp/go-tfe"
+)
+
+// apiMediaType is the media type of JSON:API requests and responses
+const apiMediaType = "application/vnd.api+json"
+
+// apiRetryRateLimited retries requests Terraform Cloud rejected for exceeding its rate limit
+func apiRetryRateLimited(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	if err != nil {
+		return false, err
+	}
+	return resp.StatusCode == http.StatusTooManyRequests, nil
+}
+
+// apiRetryBackoff waits until the rate limit resets, as told by the X-RateLimit-Reset header
+func apiRetryBackoff(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if reset, err := strconv.ParseFloat(resp.Header.Get("X-RateLimit-Reset"), 64); err == nil && reset > 0 {
+			if wait := time.Duration(reset * float64(time.Second)); wait > min {
+				min = wait
+			}
+		}
+	}
+	return retryablehttp.LinearJitterBackoff(min, max, attemptNum, resp)
+}
+
+// apiError returns the errors of a JSON:API error response
+func apiError(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return tfc.ErrUnauthorized
+	case http.StatusNotFound:
+		return tfc.ErrResourceNotFound
+	}
+	var payload struct {
+		Errors []struct {
+			Title  string `json:"title"`
+			Detail string `json:"detail"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil || len(payload.Errors) == 0 {
+		return errors.New(resp.Status)
+	}
+	errs := []string{}
+	for _, e := range payload.Errors {
+		if e.Detail == "" {
+			errs = append(errs, e.Title)
+		} else {
+			errs = append(errs, fmt.Sprintf("%s: %s", e.Title, e.Detail))
+		}
+	}
+	return errors.New(strings.Join(errs, "; "))
+}
+
+// apiRequest sends a JSON:API request for attributes the go-tfe version of the operator does not
+// support, decoding the response into v unless it is nil. Like go-tfe, it retries the requests
+// that hit the rate limit.
+func (t *TerraformCloudClient) apiRequest(method, path string, body, v interface{}) error {
+	var payload []byte
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = data
+	}
+	basePath := t.Config.BasePath
+	if basePath == "" {
+		basePath = tfc.DefaultBasePath
+	}
+	req, err := retryablehttp.NewRequest(method, strings.TrimSuffix(t.Config.Address, "/")+basePath+path, payload)
+	if err != nil {
+		return err
+	}
+	for key, values := range t.Config.Headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("Authorization", "Bearer "+t.Config.Token)
+	req.Header.Set("Accept", apiMediaType)
+	if body != nil {
+		req.Header.Set("Content-Type", apiMediaType)
+	}
+
+	client := &retryablehttp.Client{
+		HTTPClient:   t.Config.HTTPClient,
+		CheckRetry:   apiRetryRateLimited,
+		Backoff:      apiRetryBackoff,
+		ErrorHandler: retryablehttp.PassthroughErrorHandler,
+		RetryWaitMin: 100 * time.Millisecond,
+		RetryWaitMax: 400 * time.Millisecond,
+		RetryMax:     30,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%s %s: %w", method, path, apiError(resp))
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,61 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tfc "github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRequestRetriesRateLimitedRequests(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/vnd.api+json", r.Header.Get("Accept"))
+		attempts++
+		if attempts < 3 {
+			w.Header().Set("X-RateLimit-Reset", "0.01")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		fmt.Fprint(w, `{"data": {"id": "ws-123"}}`)
+	}))
+	defer srv.Close()
+
+	cloud := &TerraformCloudClient{Config: &tfc.Config{Address: srv.URL, Token: "token1", HTTPClient: srv.Client()}}
+	var workspace struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	assert.NoError(t, cloud.apiRequest(http.MethodGet, "workspaces/ws-123", nil, &workspace))
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, "ws-123", workspace.Data.ID)
+}
+
+func TestAPIRequestErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.URL.Path == "/api/v2/workspaces/ws-missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{"errors": [{"status": "422", "title": "invalid attribute",
+			"detail": "Tags regex is not a valid regular expression"}]}`)
+	}))
+	defer srv.Close()
+
+	cloud := &TerraformCloudClient{Config: &tfc.Config{Address: srv.URL, Token: "token1", HTTPClient: srv.Client()}}
+	err := cloud.apiRequest(http.MethodPatch, "workspaces/ws-123", map[string]string{}, nil)
+	assert.EqualError(t, err, "PATCH workspaces/ws-123: invalid attribute: Tags regex is not a valid regular expression")
+
+	err = cloud.apiRequest(http.MethodGet, "workspaces/ws-missing", nil, nil)
+	assert.ErrorIs(t, err, tfc.ErrResourceNotFound)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// jsonPlan holds the parts of the JSON plan representation used to detect drift
+type jsonPlan struct {
+	ResourceDrift []struct {
+		Address string `json:"address"`
+		Change  struct {
+			Actions []string `json:"actions"`
+		} `json:"change"`
+	} `json:"resource_drift"`
+}
+
+// parseResourceDrift returns the addresses of the resources that changed outside of Terraform
+func parseResourceDrift(plan []byte) ([]string, error) {
+	var parsed jsonPlan
+	if err := json.Unmarshal(plan, &parsed); err != nil {
+		return nil, fmt.Errorf("could not parse JSON plan, %v", err)
+	}
+
+	drifted := []string{}
+	for _, resource := range parsed.ResourceDrift {
+		if len(resource.Change.Actions) == 1 && resource.Change.Actions[0] == "no-op" {
+			continue
+		}
+		drifted = append(drifted, resource.Address)
+	}
+	return drifted, nil
+}
+
+// CheckDrift returns the drifted resources found by the plan of a refresh-only run
+func (t *TerraformCloudClient) CheckDrift(runID string) ([]string, error) {
+	run, err := t.Client.Runs.Read(context.TODO(), runID)
+	if err != nil {
+		return nil, err
+	}
+	if run.Plan == nil {
+		return nil, fmt.Errorf("run %s has no plan", runID)
+	}
+	plan, err := t.Client.Plans.JSONOutput(context.TODO(), run.Plan.ID)
+	if err != nil {
+		return nil, err
+	}
+	return parseResourceDrift(plan)
+}
@@ -0,0 +1,96 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseResourceDrift(t *testing.T) {
+	plan := []byte(`{
+		"format_version": "0.2",
+		"resource_drift": [
+			{"address": "aws_s3_bucket.logs", "change": {"actions": ["update"]}},
+			{"address": "aws_instance.web[0]", "change": {"actions": ["delete"]}},
+			{"address": "aws_iam_role.unchanged", "change": {"actions": ["no-op"]}}
+		]
+	}`)
+	drifted, err := parseResourceDrift(plan)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"aws_s3_bucket.logs", "aws_instance.web[0]"}, drifted)
+}
+
+func TestParseResourceDriftWithoutDrift(t *testing.T) {
+	drifted, err := parseResourceDrift([]byte(`{"format_version": "0.2"}`))
+	assert.NoError(t, err)
+	assert.Empty(t, drifted)
+}
+
+func TestParseResourceDriftWithInvalidPlan(t *testing.T) {
+	_, err := parseResourceDrift([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestDriftCheckDueAfterInterval(t *testing.T) {
+	now := time.Now()
+	workspace := &v1alpha1.Workspace{
+		Spec: v1alpha1.WorkspaceSpec{
+			DriftDetection: &v1alpha1.DriftDetection{Interval: metav1.Duration{Duration: time.Hour}},
+		},
+	}
+	assert.True(t, driftCheckDue(workspace, now))
+
+	lastCheck := metav1.NewTime(now.Add(-30 * time.Minute))
+	workspace.Status.Drift = &v1alpha1.DriftStatus{LastCheckTime: &lastCheck}
+	assert.False(t, driftCheckDue(workspace, now))
+	assert.True(t, driftCheckDue(workspace, now.Add(30*time.Minute)))
+}
+
+func TestRemediationDueOnceForEachDriftCheck(t *testing.T) {
+	workspace := &v1alpha1.Workspace{
+		Spec: v1alpha1.WorkspaceSpec{
+			DriftDetection: &v1alpha1.DriftDetection{Policy: v1alpha1.DriftPolicyRemediate},
+		},
+		Status: v1alpha1.WorkspaceStatus{
+			Drift: &v1alpha1.DriftStatus{RunID: "run-drift", DriftedResources: []string{"aws_instance.web"}},
+		},
+	}
+	assert.True(t, remediationDue(workspace))
+
+	workspace.Status.Drift.RemediationRunID = "run-remediation"
+	assert.False(t, remediationDue(workspace))
+
+	workspace.Status.Drift = &v1alpha1.DriftStatus{RunID: "run-drift", DriftedResources: []string{}}
+	assert.False(t, remediationDue(workspace))
+
+	workspace.Status.Drift.DriftedResources = []string{"aws_instance.web"}
+	workspace.Spec.DriftDetection.Policy = v1alpha1.DriftPolicyReport
+	assert.False(t, remediationDue(workspace))
+}
+
+func TestDriftCheckRunning(t *testing.T) {
+	tests := []struct {
+		status  string
+		running bool
+		planned bool
+	}{
+		{"planning", true, false},
+		{"planned", false, true},
+		{"policy_checked", false, true},
+		{"policy_soft_failed", false, true},
+		{"planned_and_finished", false, false},
+		{"errored", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			assert.Equal(t, tt.running, driftCheckRunning(&v1alpha1.DriftStatus{RunStatus: tt.status}))
+			assert.Equal(t, tt.planned, driftCheckPlanned(tt.status))
+		})
+	}
+}
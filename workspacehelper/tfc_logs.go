@@ -0,0 +1,162 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tfc "github.com/hashicorp/go-tfe"
+)
+
+const (
+	// logTailLines is how many lines of each log are kept
+	logTailLines = 200
+	// logReadTimeout bounds how long reading the logs of a finished run may take
+	logReadTimeout = 30 * time.Second
+	// redactedValue replaces sensitive values in logs
+	redactedValue = "(redacted)"
+	// minRedactedLength is the length below which sensitive values are not redacted
+	minRedactedLength = 6
+)
+
+var (
+	ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+	// Terraform draws a box around diagnostics since 0.15
+	diagnosticBox = regexp.MustCompile(`^[\s│╷╵]*`)
+)
+
+// RunLogs holds the tail of the plan and apply logs of a run
+type RunLogs struct {
+	Plan  string
+	Apply string
+}
+
+// GetRunLogs reads the logs of a finished run
+func (t *TerraformCloudClient) GetRunLogs(runID string) (*RunLogs, error) {
+	ctx, cancel := context.WithTimeout(context.TODO(), logReadTimeout)
+	defer cancel()
+
+	run, err := t.Client.Runs.Read(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := &RunLogs{}
+	if run.Plan != nil {
+		plan, err := t.Client.Plans.Read(ctx, run.Plan.ID)
+		if err != nil {
+			return nil, err
+		}
+		switch plan.Status {
+		case tfc.PlanFinished, tfc.PlanErrored, tfc.PlanCanceled:
+			reader, err := t.Client.Plans.Logs(ctx, plan.ID)
+			if err != nil {
+				return nil, err
+			}
+			if logs.Plan, err = readLog(reader); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if run.Apply != nil {
+		apply, err := t.Client.Applies.Read(ctx, run.Apply.ID)
+		if err != nil {
+			return nil, err
+		}
+		switch apply.Status {
+		case tfc.ApplyFinished, tfc.ApplyErrored, tfc.ApplyCanceled:
+			reader, err := t.Client.Applies.Logs(ctx, apply.ID)
+			if err != nil {
+				return nil, err
+			}
+			if logs.Apply, err = readLog(reader); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return logs, nil
+}
+
+func readLog(reader io.Reader) (string, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return ansiEscape.ReplaceAllString(string(data), ""), nil
+}
+
+// tailLines returns the last lines of a log
+func tailLines(log string, lines int) string {
+	split := strings.Split(strings.TrimRight(log, "\n"), "\n")
+	if len(split) > lines {
+		split = split[len(split)-lines:]
+	}
+	return strings.Join(split, "\n")
+}
+
+// redactedForms returns the text a sensitive value can appear as in a log: as is, quoted by
+// Terraform or escaped in JSON, and line by line for multi-line values like PEM keys. Longer
+// forms come first, so they are replaced before the lines they contain.
+func redactedForms(value string) []string {
+	value = strings.TrimRight(value, "\r\n")
+	forms := []string{value}
+	quoted := strconv.Quote(value)
+	forms = append(forms, quoted[1:len(quoted)-1])
+	var escaped bytes.Buffer
+	encoder := json.NewEncoder(&escaped)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err == nil {
+		escaped := strings.TrimSpace(escaped.String())
+		forms = append(forms, escaped[1:len(escaped)-1])
+	}
+	if strings.Contains(value, "\n") {
+		for _, line := range strings.Split(value, "\n") {
+			if line = strings.TrimSpace(line); len(line) >= minRedactedLength {
+				forms = append(forms, line)
+			}
+		}
+	}
+	sort.SliceStable(forms, func(i, j int) bool { return len(forms[i]) > len(forms[j]) })
+	return forms
+}
+
+// redactSensitiveValues replaces every occurrence of the sensitive values in a log. Values shorter
+// than minRedactedLength would blank out unrelated words of the log, so they are left alone and
+// counted in the returned number instead.
+func redactSensitiveValues(log string, values []string) (string, int) {
+	short := 0
+	for _, value := range values {
+		if value == "" {
+			continue
+		} else if len(strings.TrimSpace(value)) < minRedactedLength {
+			short++
+			continue
+		}
+		for _, form := range redactedForms(value) {
+			log = strings.ReplaceAll(log, form, redactedValue)
+		}
+	}
+	return log, short
+}
+
+// errorSummary returns the Terraform errors found in a log
+func errorSummary(log string) string {
+	errors := []string{}
+	for _, line := range strings.Split(log, "\n") {
+		line = strings.TrimSpace(diagnosticBox.ReplaceAllString(line, ""))
+		if strings.HasPrefix(line, "Error: ") {
+			errors = append(errors, line)
+		}
+	}
+	return strings.Join(errors, "; ")
+}
@@ -20,16 +20,16 @@ import (
 )
 
 var (
-	// AutoApply run to workspace
-	AutoApply     = true
 	AgentPageSize = 100
 )
 
-// TerraformCloudClient has a TFC Client and organization
+// TerraformCloudClient has a TFC Client. It is shared by concurrent reconciles, so the organization
+// and secrets mount path of a Workspace are passed to every call instead.
 type TerraformCloudClient struct {
-	Client           *tfc.Client
-	Organization     string
-	SecretsMountPath string
+	Client  *tfc.Client
+	Address string
+	// Config of Client, used for the API attributes Client does not support
+	Config *tfc.Config
 }
 
 func createTerraformConfig(address string, tfConfig *cliconfig.Config) (*tfc.Config, error) {
@@ -88,26 +88,56 @@ func (t *TerraformCloudClient) GetClient(tfEndpoint string) error {
 		return err
 	}
 	t.Client = client
+	t.Address = config.Address
+	t.Config = config
 	return nil
 }
 
 // CheckOrganization looks for an organization
-func (t *TerraformCloudClient) CheckOrganization() error {
-	_, err := t.Client.Organizations.Read(context.TODO(), t.Organization)
+func (t *TerraformCloudClient) CheckOrganization(organization string) error {
+	_, err := t.Client.Organizations.Read(context.TODO(), organization)
 	return err
 }
 
-func (t *TerraformCloudClient) SetTerraformVersion(workspace, terraformVersion string) error {
+func (t *TerraformCloudClient) SetTerraformVersion(workspaceID, terraformVersion string) error {
 	wsUpdateOptions := tfc.WorkspaceUpdateOptions{
 		TerraformVersion: &terraformVersion,
 	}
-	_, err := t.Client.Workspaces.Update(context.TODO(), t.Organization, workspace, wsUpdateOptions)
+	_, err := t.Client.Workspaces.UpdateByID(context.TODO(), workspaceID, wsUpdateOptions)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// SetAutoApply toggles whether runs in the workspace are applied without confirmation
+func (t *TerraformCloudClient) SetAutoApply(workspaceID string, autoApply bool) error {
+	wsUpdateOptions := tfc.WorkspaceUpdateOptions{
+		AutoApply: &autoApply,
+	}
+	_, err := t.Client.Workspaces.UpdateByID(context.TODO(), workspaceID, wsUpdateOptions)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// isAutoApply reports whether runs should be applied without waiting for approval
+func isAutoApply(instance *appv1alpha1.Workspace) bool {
+	return instance.Spec.ApplyMode != appv1alpha1.ApplyModeManual
+}
+
+// workspaceAutoApply is the auto-apply setting of the Terraform Cloud workspace. With a cost
+// policy, runs always wait for confirmation so the operator can check their cost estimate first.
+func workspaceAutoApply(instance *appv1alpha1.Workspace) bool {
+	return isAutoApply(instance) && instance.Spec.CostPolicy == nil
+}
+
+// isAdopted reports whether the Workspace is bound to a workspace that existed before the operator managed it
+func isAdopted(instance *appv1alpha1.Workspace) bool {
+	return instance.Spec.ExistingWorkspace != ""
+}
+
 // getAgentPoolID uses AgentPoolName to lookup and return AgentPoolID
 func getAgentPoolID(specTFCAgentPoolName string, agentPools []*tfc.AgentPool) (*tfc.AgentPool, error) {
 	for _, agentPool := range agentPools {
@@ -118,12 +148,12 @@ func getAgentPoolID(specTFCAgentPoolName string, agentPools []*tfc.AgentPool) (*
 	return nil, fmt.Errorf("No valid agent pools exist with name %v", specTFCAgentPoolName)
 }
 
-func (t *TerraformCloudClient) listAgentPools() ([]*tfc.AgentPool, error) {
+func (t *TerraformCloudClient) listAgentPools(organization string) ([]*tfc.AgentPool, error) {
 	options := tfc.AgentPoolListOptions{
 		ListOptions: tfc.ListOptions{PageSize: AgentPageSize},
 	}
 
-	agentpools, err := t.Client.AgentPools.List(context.TODO(), t.Organization, options)
+	agentpools, err := t.Client.AgentPools.List(context.TODO(), organization, options)
 	if err != nil {
 		return nil, fmt.Errorf("Problem fetching agent pools %s", err)
 	}
@@ -135,7 +165,7 @@ func (t *TerraformCloudClient) updateAgentPoolID(instance *appv1alpha1.Workspace
 
 	// When agent pool name provided, look it up otherwise set to id in spec
 	if instance.Spec.AgentPoolName != "" {
-		agentPools, err := t.listAgentPools()
+		agentPools, err := t.listAgentPools(instance.Spec.Organization)
 		agentPool, err := getAgentPoolID(instance.Spec.AgentPoolName, agentPools)
 		if err != nil {
 			return err
@@ -156,63 +186,156 @@ func (t *TerraformCloudClient) updateAgentPoolID(instance *appv1alpha1.Workspace
 	if agentPoolID != "" {
 		updateOptions.ExecutionMode = tfc.String("agent")
 	} else {
-		updateOptions.ExecutionMode = tfc.String("")
+		updateOptions.ExecutionMode = tfc.String(string(instance.Spec.ExecutionMode))
 	}
 
-	_, err := t.Client.Workspaces.Update(context.TODO(), t.Organization, workspace.Name, updateOptions)
+	_, err := t.Client.Workspaces.UpdateByID(context.TODO(), workspace.ID, updateOptions)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// ReadExistingWorkspace looks up a workspace to adopt by ID when the value starts with ws-, or by name
+func (t *TerraformCloudClient) ReadExistingWorkspace(organization, nameOrID string) (*tfc.Workspace, error) {
+	if strings.HasPrefix(nameOrID, "ws-") {
+		ws, err := t.Client.Workspaces.ReadByID(context.TODO(), nameOrID)
+		if err == nil {
+			if ws.Organization != nil && ws.Organization.Name != organization {
+				return nil, fmt.Errorf("workspace %s belongs to organization %q, not %q",
+					nameOrID, ws.Organization.Name, organization)
+			}
+			return ws, nil
+		} else if err != tfc.ErrResourceNotFound {
+			return nil, err
+		}
+	}
+	return t.Client.Workspaces.Read(context.TODO(), organization, nameOrID)
+}
+
+// ReadBoundWorkspace reads the workspace the Workspace is bound to by its ID, so it is found after being renamed.
+// It returns nil when the Workspace is not bound yet, or when the workspace was deleted or belongs to another organization.
+func (t *TerraformCloudClient) ReadBoundWorkspace(instance *appv1alpha1.Workspace) (*tfc.Workspace, error) {
+	if instance.Status.WorkspaceID == "" {
+		return nil, nil
+	}
+	ws, err := t.Client.Workspaces.ReadByID(context.TODO(), instance.Status.WorkspaceID)
+	if err == tfc.ErrResourceNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if ws.Organization != nil && ws.Organization.Name != instance.Spec.Organization {
+		return nil, nil
+	}
+	return ws, nil
+}
+
+// adoptsWorkspace reports whether the bound workspace is the existing workspace of the spec
+func adoptsWorkspace(instance *appv1alpha1.Workspace, bound *tfc.Workspace) bool {
+	existing := instance.Spec.ExistingWorkspace
+	return bound != nil && (bound.ID == existing || bound.Name == existing)
+}
+
+// RenameWorkspace changes the name of a workspace
+func (t *TerraformCloudClient) RenameWorkspace(workspaceID, name string) (*tfc.Workspace, error) {
+	return t.Client.Workspaces.UpdateByID(context.TODO(), workspaceID, tfc.WorkspaceUpdateOptions{Name: &name})
+}
+
 // CheckWorkspace looks for a remote tfc workspace
 func (t *TerraformCloudClient) CheckWorkspace(workspace string, instance *appv1alpha1.Workspace) (*tfc.Workspace, error) {
-	ws, err := t.Client.Workspaces.Read(context.TODO(), t.Organization, workspace)
-	if err != nil && err == tfc.ErrResourceNotFound {
-		id, wsErr := t.CreateWorkspace(workspace, instance)
-		if wsErr != nil {
-			return nil, wsErr
-		} else {
-			ws = &tfc.Workspace{ID: id}
+	var (
+		ws  *tfc.Workspace
+		err error
+	)
+	if err := validateExecutionMode(instance); err != nil {
+		return nil, err
+	}
+	if err := validateTagsRegex(instance); err != nil {
+		return nil, err
+	}
+
+	created := false
+	adopted := isAdopted(instance)
+	bound, err := t.ReadBoundWorkspace(instance)
+	if err != nil {
+		return nil, err
+	}
+	if adopted && !adoptsWorkspace(instance, bound) {
+		existing := instance.Spec.ExistingWorkspace
+		ws, err = t.ReadExistingWorkspace(instance.Spec.Organization, existing)
+		if err == tfc.ErrResourceNotFound && bound != nil && !strings.HasPrefix(existing, "ws-") {
+			// The adopted workspace was renamed since it was adopted by name
+			ws, err = bound, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("could not find existing workspace %q: %s", existing, err)
+		}
+		workspace = ws.Name
+	} else if bound != nil {
+		ws = bound
+		workspace = ws.Name
+	} else {
+		ws, err = t.Client.Workspaces.Read(context.TODO(), instance.Spec.Organization, workspace)
+		if err != nil && err == tfc.ErrResourceNotFound {
+			id, wsErr := t.CreateWorkspace(workspace, instance)
+			if wsErr != nil {
+				return nil, wsErr
+			}
+			ws = &tfc.Workspace{ID: id, Name: workspace, AutoApply: workspaceAutoApply(instance)}
+			created = true
 			err = nil
+		} else if err != nil {
+			return nil, err
 		}
-		ws = &tfc.Workspace{ID: id}
-	} else if err != nil {
-		return nil, err
 	}
 
 	if instance.Spec.SSHKeyID != "" {
-		_, err = t.AssignWorkspaceSSHKey(ws.ID, instance.Spec.SSHKeyID)
+		_, err = t.AssignWorkspaceSSHKey(instance.Spec.Organization, ws.ID, instance.Spec.SSHKeyID)
 		if err != nil {
 			return nil, fmt.Errorf("Error while assigning ssh key to workspace: %s", err)
 		}
-	} else if ws.SSHKey != nil {
+	} else if ws.SSHKey != nil && !adopted {
 		_, err = t.UnassignWorkspaceSSHKey(ws.ID)
 		if err != nil {
 			return nil, fmt.Errorf("Error while unassigning ssh key to workspace: %s", err)
 		}
 	}
 
-	if instance.Spec.TerraformVersion != ws.TerraformVersion {
-		err = t.SetTerraformVersion(workspace, instance.Spec.TerraformVersion)
+	if instance.Spec.TerraformVersion != ws.TerraformVersion && (!adopted || instance.Spec.TerraformVersion != "") {
+		err = t.SetTerraformVersion(ws.ID, instance.Spec.TerraformVersion)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	if instance.Spec.AgentPoolID != ws.AgentPoolID {
+	declaresAutoApply := instance.Spec.ApplyMode != "" || instance.Spec.CostPolicy != nil
+	if autoApply := workspaceAutoApply(instance); autoApply != ws.AutoApply && (!adopted || declaresAutoApply) {
+		err = t.SetAutoApply(ws.ID, autoApply)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if instance.Spec.AgentPoolID != ws.AgentPoolID && (!adopted || declaresAgentPool(instance)) {
 		err := t.updateAgentPoolID(instance, ws)
 		if err != nil {
 			return nil, fmt.Errorf("error while updating Agent Pool ID settings for workspace %q: %s", ws.Name, err)
 		}
 	}
 
+	// Settings of a new workspace were set when it was created
+	if !created {
+		if err := t.UpdateWorkspaceSettings(instance, ws); err != nil {
+			return nil, fmt.Errorf("error while updating settings of workspace %q: %s", ws.Name, err)
+		}
+	}
+
 	return ws, err
 }
 
-// CreateWorkspace creates a Terraform Cloud Workspace that auto-applies
+// CreateWorkspace creates a Terraform Cloud Workspace that auto-applies unless the apply mode is manual
 func (t *TerraformCloudClient) CreateWorkspace(workspace string, instance *appv1alpha1.Workspace) (string, error) {
+	autoApply := workspaceAutoApply(instance)
 	var tfVersion string
 	if instance.Spec.TerraformVersion == "" {
 		tfVersion = "latest"
@@ -221,25 +344,26 @@ func (t *TerraformCloudClient) CreateWorkspace(workspace string, instance *appv1
 	}
 
 	options := tfc.WorkspaceCreateOptions{
-		AutoApply:        &AutoApply,
+		AutoApply:        &autoApply,
 		Name:             &workspace,
 		TerraformVersion: &tfVersion,
 	}
 
+	createWorkspaceSettings(instance, &options)
+
 	if instance.Spec.VCS != nil {
-		options.VCSRepo = &tfc.VCSRepoOptions{
-			Branch:            &instance.Spec.VCS.Branch,
-			Identifier:        &instance.Spec.VCS.RepoIdentifier,
-			IngressSubmodules: &instance.Spec.VCS.IngressSubmodules,
-			OAuthTokenID:      &instance.Spec.VCS.TokenID,
+		options.VCSRepo = vcsRepoOptions(instance.Spec.VCS)
+		if instance.Spec.VCS.WorkingDirectory != "" {
+			options.WorkingDirectory = &instance.Spec.VCS.WorkingDirectory
 		}
+		options.TriggerPrefixes = instance.Spec.VCS.TriggerPrefixes
 	}
 
 	if instance.Spec.AgentPoolID != "" {
 		options.AgentPoolID = &instance.Spec.AgentPoolID
 		options.ExecutionMode = tfc.String("agent")
 	} else if instance.Spec.AgentPoolName != "" {
-		agentPools, err := t.listAgentPools()
+		agentPools, err := t.listAgentPools(instance.Spec.Organization)
 		if err != nil {
 			return "", err
 		}
@@ -251,7 +375,7 @@ func (t *TerraformCloudClient) CreateWorkspace(workspace string, instance *appv1
 		options.ExecutionMode = tfc.String("agent")
 	}
 
-	ws, err := t.Client.Workspaces.Create(context.TODO(), t.Organization, options)
+	ws, err := t.Client.Workspaces.Create(context.TODO(), instance.Spec.Organization, options)
 	if err != nil {
 		return "", err
 	}
@@ -259,8 +383,8 @@ func (t *TerraformCloudClient) CreateWorkspace(workspace string, instance *appv1
 }
 
 // GetSSHKeyByNameOrID Lookup provided Key ID by name or ID, return ID.
-func (t *TerraformCloudClient) GetSSHKeyByNameOrID(SSHKeyID string) (string, error) {
-	sshKeys, err := t.Client.SSHKeys.List(context.TODO(), t.Organization, tfc.SSHKeyListOptions{})
+func (t *TerraformCloudClient) GetSSHKeyByNameOrID(organization, SSHKeyID string) (string, error) {
+	sshKeys, err := t.Client.SSHKeys.List(context.TODO(), organization, tfc.SSHKeyListOptions{})
 	if err != nil {
 		return "", err
 	}
@@ -274,9 +398,9 @@ func (t *TerraformCloudClient) GetSSHKeyByNameOrID(SSHKeyID string) (string, err
 }
 
 // AssignWorkspaceSSHKey to Terraform Cloud Workspace
-func (t *TerraformCloudClient) AssignWorkspaceSSHKey(workspaceID string, SSHKeyID string) (string, error) {
+func (t *TerraformCloudClient) AssignWorkspaceSSHKey(organization, workspaceID string, SSHKeyID string) (string, error) {
 
-	sshKey, err := t.GetSSHKeyByNameOrID(SSHKeyID)
+	sshKey, err := t.GetSSHKeyByNameOrID(organization, SSHKeyID)
 	if err != nil {
 		return "", err
 	}
@@ -354,7 +478,7 @@ func (t *TerraformCloudClient) CheckNotifications(instance *appv1alpha1.Workspac
 		return err
 	}
 
-	if len(notifications.Items) == 0 && len(instance.Spec.Notifications) == 0 {
+	if len(instance.Spec.Notifications) == 0 && (len(notifications.Items) == 0 || isAdopted(instance)) {
 		return nil
 	}
 
@@ -4,21 +4,23 @@
 package workspacehelper
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
+	"github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
 	"github.com/stretchr/testify/assert"
 )
 
 func setupClient(t *testing.T, tfAddress string) (*TerraformCloudClient, error) {
-	testOrganization := os.Getenv("TF_ORG")
 	if os.Getenv("TF_ACC") == "" || os.Getenv("TF_CLI_CONFIG_FILE") == "" {
 		t.Skipf("this test requires Terraform Cloud and Enterprise access and credentials;" +
 			"set TF_ACC=1 and TF_CLI_CONFIG_FILE to run it")
 	}
-	tfClient := &TerraformCloudClient{
-		Organization: testOrganization,
-	}
+	tfClient := &TerraformCloudClient{}
 	err := tfClient.GetClient(tfAddress)
 	return tfClient, err
 }
@@ -32,7 +34,7 @@ func TestOrganizationTerraformCloud(t *testing.T) {
 	tfClient, err := setupClient(t, "")
 	assert.NoError(t, err)
 
-	err = tfClient.CheckOrganization()
+	err = tfClient.CheckOrganization(os.Getenv("TF_ORG"))
 	assert.NoError(t, err)
 }
 
@@ -43,7 +45,7 @@ func TestOrganizationTerraformEnterprise(t *testing.T) {
 	tfClient, err := setupClient(t, os.Getenv("TF_URL"))
 	assert.NoError(t, err)
 
-	err = tfClient.CheckOrganization()
+	err = tfClient.CheckOrganization(os.Getenv("TF_ORG"))
 	assert.NoError(t, err)
 }
 
@@ -54,7 +56,129 @@ func TestOrganizationTerraformEnterpriseNotFound(t *testing.T) {
 	tfClient, err := setupClient(t, os.Getenv("TF_URL"))
 	assert.NoError(t, err)
 
-	tfClient.Organization = "doesnotexist"
-	err = tfClient.CheckOrganization()
+	err = tfClient.CheckOrganization("doesnotexist")
 	assert.Error(t, err)
 }
+
+func TestReadExistingWorkspace(t *testing.T) {
+	workspaceResponse := `{"data": {"id": "ws-abc123", "type": "workspaces",
+		"attributes": {"name": "adopted"},
+		"relationships": {"organization": {"data": {"id": "%s", "type": "organizations"}}}}}`
+	tests := []struct {
+		name         string
+		nameOrID     string
+		organization string
+		wantPath     string
+		wantErr      bool
+	}{
+		{
+			name:         "By ID",
+			nameOrID:     "ws-abc123",
+			organization: "world",
+			wantPath:     "/api/v2/workspaces/ws-abc123",
+		},
+		{
+			name:         "By name",
+			nameOrID:     "adopted",
+			organization: "world",
+			wantPath:     "/api/v2/organizations/world/workspaces/adopted",
+		},
+		{
+			name:         "By ID in another organization",
+			nameOrID:     "ws-abc123",
+			organization: "elsewhere",
+			wantPath:     "/api/v2/workspaces/ws-abc123",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				paths = append(paths, r.URL.Path)
+				w.Header().Set("Content-Type", "application/vnd.api+json")
+				fmt.Fprintf(w, workspaceResponse, tt.organization)
+			}))
+			defer srv.Close()
+			client, err := tfe.NewClient(&tfe.Config{
+				Address:    srv.URL,
+				Token:      "token1",
+				HTTPClient: srv.Client(),
+			})
+			assert.NoError(t, err)
+
+			cloud := &TerraformCloudClient{
+				Client: client,
+			}
+			ws, err := cloud.ReadExistingWorkspace("world", tt.nameOrID)
+			assert.Contains(t, paths, tt.wantPath)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "ws-abc123", ws.ID)
+			assert.Equal(t, "adopted", ws.Name)
+		})
+	}
+}
+
+func TestReadBoundWorkspace(t *testing.T) {
+	workspaceResponse := `{"data": {"id": "ws-abc123", "type": "workspaces",
+		"attributes": {"name": "renamed"},
+		"relationships": {"organization": {"data": {"id": "%s", "type": "organizations"}}}}}`
+	tests := []struct {
+		name         string
+		workspaceID  string
+		organization string
+		status       int
+		wantFound    bool
+	}{
+		{name: "Renamed", workspaceID: "ws-abc123", organization: "world", status: http.StatusOK, wantFound: true},
+		{name: "Not bound", organization: "world", status: http.StatusOK},
+		{name: "Deleted", workspaceID: "ws-abc123", organization: "world", status: http.StatusNotFound},
+		{name: "Another organization", workspaceID: "ws-abc123", organization: "elsewhere", status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				paths = append(paths, r.URL.Path)
+				w.Header().Set("Content-Type", "application/vnd.api+json")
+				w.WriteHeader(tt.status)
+				fmt.Fprintf(w, workspaceResponse, tt.organization)
+			}))
+			defer srv.Close()
+			client, err := tfe.NewClient(&tfe.Config{
+				Address:    srv.URL,
+				Token:      "token1",
+				HTTPClient: srv.Client(),
+			})
+			assert.NoError(t, err)
+
+			cloud := &TerraformCloudClient{
+				Client: client,
+			}
+			instance := &v1alpha1.Workspace{Spec: v1alpha1.WorkspaceSpec{Organization: "world"}}
+			instance.Status.WorkspaceID = tt.workspaceID
+			ws, err := cloud.ReadBoundWorkspace(instance)
+			assert.NoError(t, err)
+			if !tt.wantFound {
+				assert.Nil(t, ws)
+				return
+			}
+			assert.Contains(t, paths, "/api/v2/workspaces/ws-abc123")
+			assert.Equal(t, "renamed", ws.Name)
+		})
+	}
+}
+
+func TestAdoptsWorkspace(t *testing.T) {
+	instance := &v1alpha1.Workspace{Spec: v1alpha1.WorkspaceSpec{ExistingWorkspace: "adopted"}}
+	assert.False(t, adoptsWorkspace(instance, nil))
+	assert.True(t, adoptsWorkspace(instance, &tfe.Workspace{ID: "ws-abc123", Name: "adopted"}))
+	assert.False(t, adoptsWorkspace(instance, &tfe.Workspace{ID: "ws-abc123", Name: "renamed"}))
+
+	instance.Spec.ExistingWorkspace = "ws-abc123"
+	assert.True(t, adoptsWorkspace(instance, &tfe.Workspace{ID: "ws-abc123", Name: "renamed"}))
+}
@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	tfc "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+)
+
+var (
+	sentinelPolicy = regexp.MustCompile(`^## Policy \d+: (.+) \(([a-z-]+)\)$`)
+	sentinelResult = regexp.MustCompile(`^Result: (true|false)$`)
+)
+
+// policyStatusSeverity orders policy check statuses so that the worst one describes the run
+var policyStatusSeverity = map[tfc.PolicyStatus]int{
+	tfc.PolicyPasses:      1,
+	tfc.PolicyOverridden:  2,
+	tfc.PolicyPending:     3,
+	tfc.PolicyQueued:      3,
+	tfc.PolicyUnreachable: 4,
+	tfc.PolicyCanceled:    4,
+	tfc.PolicySoftFailed:  5,
+	tfc.PolicyErrored:     6,
+	tfc.PolicyHardFailed:  7,
+}
+
+// parseSentinelOutput returns the summary and the per-policy results of the Sentinel output of a policy check
+func parseSentinelOutput(output string) (string, []v1alpha1.PolicyResult) {
+	summary := []string{}
+	policies := []v1alpha1.PolicyResult{}
+	var current *v1alpha1.PolicyResult
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Sentinel Result:") || strings.HasSuffix(line, "policies evaluated.") {
+			summary = append(summary, strings.TrimSuffix(line, "."))
+		} else if match := sentinelPolicy.FindStringSubmatch(line); match != nil {
+			policies = append(policies, v1alpha1.PolicyResult{Name: match[1], EnforcementLevel: match[2]})
+			current = &policies[len(policies)-1]
+		} else if match := sentinelResult.FindStringSubmatch(line); match != nil && current != nil {
+			current.Passed = match[1] == "true"
+			current = nil
+		}
+	}
+	return strings.Join(summary, ", "), policies
+}
+
+// GetPolicyChecks reads the policy checks of a run, nil when no policy applies to the workspace
+func (t *TerraformCloudClient) GetPolicyChecks(runID string) (*v1alpha1.PolicyCheckStatus, error) {
+	checks, err := t.Client.PolicyChecks.List(context.TODO(), runID, tfc.PolicyCheckListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(checks.Items) == 0 {
+		return nil, nil
+	}
+
+	status := &v1alpha1.PolicyCheckStatus{RunID: runID}
+	summaries := []string{}
+	for _, check := range checks.Items {
+		if policyStatusSeverity[check.Status] > policyStatusSeverity[tfc.PolicyStatus(status.Status)] {
+			status.Status = string(check.Status)
+		}
+		if check.Result != nil {
+			status.Passed += check.Result.Passed
+			status.AdvisoryFailed += check.Result.AdvisoryFailed
+			status.SoftFailed += check.Result.SoftFailed
+			status.HardFailed += check.Result.HardFailed
+		}
+
+		reader, err := t.Client.PolicyChecks.Logs(context.TODO(), check.ID)
+		if err != nil {
+			return nil, err
+		}
+		output, err := readLog(reader)
+		if err != nil {
+			return nil, err
+		}
+		summary, policies := parseSentinelOutput(output)
+		if summary != "" {
+			summaries = append(summaries, summary)
+		}
+		status.Policies = append(status.Policies, policies...)
+	}
+	status.Summary = strings.Join(summaries, "; ")
+	return status, nil
+}
+
+// OverridePolicyChecks overrides the soft-mandatory policy checks that failed for a run
+func (t *TerraformCloudClient) OverridePolicyChecks(runID string) error {
+	checks, err := t.Client.PolicyChecks.List(context.TODO(), runID, tfc.PolicyCheckListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, check := range checks.Items {
+		if check.Status != tfc.PolicySoftFailed || (check.Actions != nil && !check.Actions.IsOverridable) {
+			continue
+		}
+		if _, err := t.Client.PolicyChecks.Override(context.TODO(), check.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+const sentinelOutput = `Sentinel Result: false
+
+This result means that Sentinel policies returned false and the protected
+behavior is not allowed by Sentinel policies.
+
+2 policies evaluated.
+
+## Policy 1: aws/restrict-instance-type (soft-mandatory)
+
+Result: false
+
+FALSE - restrict-instance-type.sentinel:12:1 - Rule "main"
+
+## Policy 2: aws/require-tags (advisory)
+
+Result: true
+
+TRUE - require-tags.sentinel:5:1 - Rule "main"
+`
+
+func TestParseSentinelOutput(t *testing.T) {
+	summary, policies := parseSentinelOutput(sentinelOutput)
+	assert.Equal(t, "Sentinel Result: false, 2 policies evaluated", summary)
+	assert.Equal(t, []v1alpha1.PolicyResult{
+		{Name: "aws/restrict-instance-type", EnforcementLevel: "soft-mandatory", Passed: false},
+		{Name: "aws/require-tags", EnforcementLevel: "advisory", Passed: true},
+	}, policies)
+	assert.Equal(t, []string{"aws/restrict-instance-type (soft-mandatory)"},
+		failedPolicies(&v1alpha1.PolicyCheckStatus{Policies: policies}))
+}
+
+func TestParseSentinelOutputWithoutPolicies(t *testing.T) {
+	summary, policies := parseSentinelOutput("")
+	assert.Equal(t, "", summary)
+	assert.Empty(t, policies)
+}
@@ -9,22 +9,20 @@ import (
 	"time"
 
 	tfc "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
 )
 
 var (
-	autoQueueRuns         = false
-	speculative           = false
-	isDestroy             = true
-	basepath              = "/tmp"
-	moduleDirectory       = fmt.Sprintf("%s/%s", basepath, "module")
-	configurationFilePath = fmt.Sprintf("%s/%s", moduleDirectory, "main.tf")
-	interval              = 30 * time.Second
+	autoQueueRuns = false
+	speculative   = false
+	isDestroy     = true
+	interval      = 30 * time.Second
 )
 
-// UploadConfigurationFile uploads the main.tf to a configuration version
-func (t *TerraformCloudClient) UploadConfigurationFile(uploadURL string) error {
-	if err := t.Client.ConfigurationVersions.Upload(context.TODO(), uploadURL, moduleDirectory); err != nil {
-		return fmt.Errorf("error, %v, %v", err, moduleDirectory)
+// UploadConfigurationFile uploads the Terraform files of a directory to a configuration version
+func (t *TerraformCloudClient) UploadConfigurationFile(uploadURL, directory string) error {
+	if err := t.Client.ConfigurationVersions.Upload(context.TODO(), uploadURL, directory); err != nil {
+		return fmt.Errorf("error, %v, %v", err, directory)
 	}
 	return nil
 }
@@ -42,6 +40,28 @@ func (t *TerraformCloudClient) CreateConfigurationVersion(workspaceID string) (*
 	return configVersion, nil
 }
 
+// CreateSpeculativeConfigurationVersion creates a configuration version for plan-only runs
+func (t *TerraformCloudClient) CreateSpeculativeConfigurationVersion(workspaceID string) (*tfc.ConfigurationVersion, error) {
+	speculative := true
+	options := tfc.ConfigurationVersionCreateOptions{
+		AutoQueueRuns: &autoQueueRuns,
+		Speculative:   &speculative,
+	}
+	return t.Client.ConfigurationVersions.Create(context.TODO(), workspaceID, options)
+}
+
+// UploadedConfigurationVersion reads a configuration version, and returns nil until its upload is processed
+func (t *TerraformCloudClient) UploadedConfigurationVersion(configVersionID string) (*tfc.ConfigurationVersion, error) {
+	configVersion, err := t.Client.ConfigurationVersions.Read(context.TODO(), configVersionID)
+	if err != nil {
+		return nil, err
+	}
+	if configVersion.Status != tfc.ConfigurationUploaded {
+		return nil, nil
+	}
+	return configVersion, nil
+}
+
 // CheckRun gets the run status
 func (t *TerraformCloudClient) CheckRun(runID string) (string, error) {
 	if runID == "" {
@@ -74,10 +94,176 @@ func isPending(status string) bool {
 	}
 }
 
+// isConfirmable reports whether a run is waiting for confirmation before it can be applied
+func isConfirmable(status string) bool {
+	switch tfc.RunStatus(status) {
+	case tfc.RunPlanned, tfc.RunCostEstimated, tfc.RunPolicyChecked, tfc.RunPolicyOverride:
+		return true
+	default:
+		return false
+	}
+}
+
 func isError(status string) bool {
 	return tfc.RunStatus(status) == tfc.RunErrored
 }
 
+// GetRunActions reads what can currently be done with a run
+func (t *TerraformCloudClient) GetRunActions(runID string) (*tfc.RunActions, error) {
+	run, err := t.Client.Runs.Read(context.TODO(), runID)
+	if err != nil {
+		return nil, err
+	}
+	if run.Actions == nil {
+		return &tfc.RunActions{}, nil
+	}
+	return run.Actions, nil
+}
+
+// ApplyRun confirms a run that is waiting for approval
+func (t *TerraformCloudClient) ApplyRun(runID string, comment string) error {
+	return t.Client.Runs.Apply(context.TODO(), runID, tfc.RunApplyOptions{
+		Comment: &comment,
+	})
+}
+
+// DiscardRun discards a run that is waiting for approval
+func (t *TerraformCloudClient) DiscardRun(runID string, comment string) error {
+	return t.Client.Runs.Discard(context.TODO(), runID, tfc.RunDiscardOptions{
+		Comment: &comment,
+	})
+}
+
+// SupersedeRun discards a run waiting for confirmation, or cancels it when cancel is true and it
+// did not start applying. It returns the status the run moves to, empty when the run was left alone.
+func (t *TerraformCloudClient) SupersedeRun(runID string, cancel bool, comment string) (tfc.RunStatus, error) {
+	run, err := t.Client.Runs.Read(context.TODO(), runID)
+	if err != nil {
+		return "", err
+	}
+	if run.Actions == nil {
+		return "", nil
+	}
+	if run.Actions.IsDiscardable {
+		err := t.Client.Runs.Discard(context.TODO(), runID, tfc.RunDiscardOptions{Comment: &comment})
+		return tfc.RunDiscarded, err
+	}
+	switch run.Status {
+	case tfc.RunConfirmed, tfc.RunApplyQueued, tfc.RunApplying:
+		// Interrupting an apply could leave resources half created
+		return "", nil
+	}
+	if cancel && run.Actions.IsCancelable {
+		err := t.Client.Runs.Cancel(context.TODO(), runID, tfc.RunCancelOptions{Comment: &comment})
+		return tfc.RunCanceled, err
+	}
+	return "", nil
+}
+
+// CreateRefreshOnlyRun queues a refresh-only run. On a speculative configuration version it is a
+// plan that never locks the workspace, otherwise it waits for confirmation instead of being applied.
+func (t *TerraformCloudClient) CreateRefreshOnlyRun(workspaceID string, configVersion *tfc.ConfigurationVersion) (*tfc.Run, error) {
+	message := fmt.Sprintf("%s, drift detection", TerraformOperator)
+	refreshOnly := true
+	options := tfc.RunCreateOptions{
+		Message:              &message,
+		RefreshOnly:          &refreshOnly,
+		ConfigurationVersion: configVersion,
+		Workspace: &tfc.Workspace{
+			ID: workspaceID,
+		},
+	}
+	if configVersion == nil {
+		autoApply := false
+		options.AutoApply = &autoApply
+	}
+	return t.Client.Runs.Create(context.TODO(), options)
+}
+
+// CreateOperationRun queues a run for the operation of a Run object. A plan needs the speculative
+// configuration version to plan, the other operations run the latest configuration of the workspace.
+func (t *TerraformCloudClient) CreateOperationRun(workspaceID string, operation v1alpha1.RunOperation, message string,
+	autoApply bool, configVersion *tfc.ConfigurationVersion) (*tfc.Run, error) {
+	options := tfc.RunCreateOptions{
+		Message:              &message,
+		ConfigurationVersion: configVersion,
+		Workspace: &tfc.Workspace{
+			ID: workspaceID,
+		},
+	}
+	// Speculative plans are never applied
+	if operation != v1alpha1.RunOperationPlan {
+		options.AutoApply = &autoApply
+	}
+	switch operation {
+	case v1alpha1.RunOperationDestroy:
+		options.IsDestroy = &isDestroy
+	case v1alpha1.RunOperationRefreshOnly:
+		refreshOnly := true
+		options.RefreshOnly = &refreshOnly
+	}
+	return t.Client.Runs.Create(context.TODO(), options)
+}
+
+// FindRun returns the most recent run of a workspace with the message, nil when there is none
+func (t *TerraformCloudClient) FindRun(workspaceID, message string) (*tfc.Run, error) {
+	runs, err := t.Client.Runs.List(context.TODO(), workspaceID, tfc.RunListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs.Items {
+		if run.Message == message {
+			return run, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetRunChanges reads the resource changes of a planned run, and of its apply when applied is true
+func (t *TerraformCloudClient) GetRunChanges(runID string, applied bool) (*v1alpha1.RunChanges, error) {
+	run, err := t.Client.Runs.ReadWithOptions(context.TODO(), runID, &tfc.RunReadOptions{Include: "plan,apply"})
+	if err != nil {
+		return nil, err
+	}
+	if run.Plan == nil {
+		return nil, fmt.Errorf("run %s has no plan", runID)
+	}
+
+	changes := &v1alpha1.RunChanges{
+		RunID:      runID,
+		HasChanges: run.Plan.HasChanges,
+		Plan: v1alpha1.ResourceCounts{
+			Additions:    run.Plan.ResourceAdditions,
+			Changes:      run.Plan.ResourceChanges,
+			Destructions: run.Plan.ResourceDestructions,
+		},
+	}
+	changes.Summary = formatResourceCounts(changes.Plan)
+	if applied && run.Apply != nil {
+		changes.Apply = &v1alpha1.ResourceCounts{
+			Additions:    run.Apply.ResourceAdditions,
+			Changes:      run.Apply.ResourceChanges,
+			Destructions: run.Apply.ResourceDestructions,
+		}
+		changes.Summary = formatResourceCounts(*changes.Apply)
+	}
+	return changes, nil
+}
+
+// GetCostEstimate reads the cost estimate of a run, nil when cost estimation is disabled
+func (t *TerraformCloudClient) GetCostEstimate(runID string) (*tfc.CostEstimate, error) {
+	run, err := t.Client.Runs.ReadWithOptions(context.TODO(), runID, &tfc.RunReadOptions{Include: "cost_estimate"})
+	if err != nil {
+		return nil, err
+	}
+	return run.CostEstimate, nil
+}
+
+// formatResourceCounts formats resource counts as +added ~changed -destroyed
+func formatResourceCounts(counts v1alpha1.ResourceCounts) string {
+	return fmt.Sprintf("+%d ~%d -%d", counts.Additions, counts.Changes, counts.Destructions)
+}
+
 // DeleteRuns cancels runs that haven't been applied or planned
 func (t *TerraformCloudClient) DeleteRuns(workspaceID string) error {
 	message := "operator, finalizer, cancelling run"
@@ -0,0 +1,252 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+const runWithChangesResponse = `{"data": {"id": "run-123", "type": "runs",
+	"attributes": {"status": "%s"},
+	"relationships": {
+		"plan": {"data": {"id": "plan-123", "type": "plans"}},
+		"apply": {"data": {"id": "apply-123", "type": "applies"}}}},
+	"included": [
+		{"id": "plan-123", "type": "plans", "attributes": {"has-changes": true,
+			"resource-additions": 3, "resource-changes": 1, "resource-destructions": 0}},
+		{"id": "apply-123", "type": "applies", "attributes": {
+			"resource-additions": 2, "resource-changes": 1, "resource-destructions": 0}}]}`
+
+func TestGetRunChanges(t *testing.T) {
+	for _, applied := range []bool{false, true} {
+		t.Run(fmt.Sprintf("applied %t", applied), func(t *testing.T) {
+			var include string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/v2/runs/run-123" {
+					include = r.URL.Query().Get("include")
+				}
+				w.Header().Set("Content-Type", "application/vnd.api+json")
+				fmt.Fprintf(w, runWithChangesResponse, "applied")
+			}))
+			defer srv.Close()
+			client, err := tfe.NewClient(&tfe.Config{
+				Address:    srv.URL,
+				Token:      "token1",
+				HTTPClient: srv.Client(),
+			})
+			assert.NoError(t, err)
+
+			cloud := &TerraformCloudClient{Client: client}
+			changes, err := cloud.GetRunChanges("run-123", applied)
+			assert.NoError(t, err)
+			assert.Equal(t, "plan,apply", include)
+			assert.True(t, changes.HasChanges)
+			assert.Equal(t, v1alpha1.ResourceCounts{Additions: 3, Changes: 1}, changes.Plan)
+			if applied {
+				assert.Equal(t, &v1alpha1.ResourceCounts{Additions: 2, Changes: 1}, changes.Apply)
+				assert.Equal(t, "+2 ~1 -0", changes.Summary)
+			} else {
+				assert.Nil(t, changes.Apply)
+				assert.Equal(t, "+3 ~1 -0", changes.Summary)
+			}
+		})
+	}
+}
+
+func TestSupersedeRun(t *testing.T) {
+	runResponse := `{"data": {"id": "run-123", "type": "runs",
+		"attributes": {"status": "%s", "actions": {"is-cancelable": %t, "is-discardable": %t}}}}`
+	tests := []struct {
+		name        string
+		status      string
+		cancelable  bool
+		discardable bool
+		cancel      bool
+		want        tfe.RunStatus
+		wantPath    string
+	}{
+		{"Discard run waiting for confirmation", "planned", false, true, false, tfe.RunDiscarded, "/api/v2/runs/run-123/actions/discard"},
+		{"Cancel run planning", "planning", true, false, true, tfe.RunCanceled, "/api/v2/runs/run-123/actions/cancel"},
+		{"Let run planning finish", "planning", true, false, false, "", ""},
+		{"Never cancel run applying", "applying", true, false, true, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actions []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					actions = append(actions, r.URL.Path)
+					w.WriteHeader(http.StatusAccepted)
+					return
+				}
+				w.Header().Set("Content-Type", "application/vnd.api+json")
+				fmt.Fprintf(w, runResponse, tt.status, tt.cancelable, tt.discardable)
+			}))
+			defer srv.Close()
+			client, err := tfe.NewClient(&tfe.Config{
+				Address:    srv.URL,
+				Token:      "token1",
+				HTTPClient: srv.Client(),
+			})
+			assert.NoError(t, err)
+
+			cloud := &TerraformCloudClient{Client: client}
+			status, err := cloud.SupersedeRun("run-123", tt.cancel, "superseded")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, status)
+			if tt.wantPath == "" {
+				assert.Empty(t, actions)
+			} else {
+				assert.Equal(t, []string{tt.wantPath}, actions)
+			}
+		})
+	}
+}
+
+func TestCreateOperationRun(t *testing.T) {
+	tests := []struct {
+		operation     v1alpha1.RunOperation
+		autoApply     bool
+		wantAutoApply interface{}
+		isDestroy     interface{}
+		refreshOnly   interface{}
+		configVersion *tfe.ConfigurationVersion
+	}{
+		{operation: v1alpha1.RunOperationPlan, configVersion: &tfe.ConfigurationVersion{ID: "cv-123"}},
+		{operation: v1alpha1.RunOperationApply, autoApply: true, wantAutoApply: true},
+		{operation: v1alpha1.RunOperationApply, wantAutoApply: false},
+		{operation: v1alpha1.RunOperationDestroy, autoApply: true, wantAutoApply: true, isDestroy: true},
+		{operation: v1alpha1.RunOperationRefreshOnly, autoApply: true, wantAutoApply: true, refreshOnly: true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s auto-apply %t", tt.operation, tt.autoApply), func(t *testing.T) {
+			var body struct {
+				Data struct {
+					Attributes    map[string]interface{} `json:"attributes"`
+					Relationships map[string]struct {
+						Data struct {
+							ID string `json:"id"`
+						} `json:"data"`
+					} `json:"relationships"`
+				} `json:"data"`
+			}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost && r.URL.Path == "/api/v2/runs" {
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				}
+				w.Header().Set("Content-Type", "application/vnd.api+json")
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"data": {"id": "run-123", "type": "runs", "attributes": {"status": "pending"}}}`)
+			}))
+			defer srv.Close()
+			client, err := tfe.NewClient(&tfe.Config{
+				Address:    srv.URL,
+				Token:      "token1",
+				HTTPClient: srv.Client(),
+			})
+			assert.NoError(t, err)
+
+			cloud := &TerraformCloudClient{Client: client}
+			run, err := cloud.CreateOperationRun("ws-123", tt.operation, "terraform-k8s, Run test", tt.autoApply, tt.configVersion)
+			assert.NoError(t, err)
+			assert.Equal(t, "run-123", run.ID)
+			attributes := body.Data.Attributes
+			assert.Equal(t, tt.wantAutoApply, attributes["auto-apply"])
+			assert.Equal(t, tt.isDestroy, attributes["is-destroy"])
+			assert.Equal(t, tt.refreshOnly, attributes["refresh-only"])
+			assert.Equal(t, "terraform-k8s, Run test", attributes["message"])
+			configVersionID := ""
+			if tt.configVersion != nil {
+				configVersionID = tt.configVersion.ID
+			}
+			assert.Equal(t, configVersionID, body.Data.Relationships["configuration-version"].Data.ID)
+		})
+	}
+}
+
+func TestCreateRefreshOnlyRun(t *testing.T) {
+	tests := []struct {
+		name          string
+		configVersion *tfe.ConfigurationVersion
+		wantAutoApply interface{}
+	}{
+		{name: "Speculative", configVersion: &tfe.ConfigurationVersion{ID: "cv-123"}},
+		{name: "Latest configuration", wantAutoApply: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body struct {
+				Data struct {
+					Attributes    map[string]interface{} `json:"attributes"`
+					Relationships map[string]struct {
+						Data struct {
+							ID string `json:"id"`
+						} `json:"data"`
+					} `json:"relationships"`
+				} `json:"data"`
+			}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost && r.URL.Path == "/api/v2/runs" {
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				}
+				w.Header().Set("Content-Type", "application/vnd.api+json")
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"data": {"id": "run-123", "type": "runs", "attributes": {"status": "pending"}}}`)
+			}))
+			defer srv.Close()
+			client, err := tfe.NewClient(&tfe.Config{
+				Address:    srv.URL,
+				Token:      "token1",
+				HTTPClient: srv.Client(),
+			})
+			assert.NoError(t, err)
+
+			cloud := &TerraformCloudClient{Client: client}
+			run, err := cloud.CreateRefreshOnlyRun("ws-123", tt.configVersion)
+			assert.NoError(t, err)
+			assert.Equal(t, "run-123", run.ID)
+			assert.Equal(t, true, body.Data.Attributes["refresh-only"])
+			assert.Equal(t, tt.wantAutoApply, body.Data.Attributes["auto-apply"])
+			configVersionID := ""
+			if tt.configVersion != nil {
+				configVersionID = tt.configVersion.ID
+			}
+			assert.Equal(t, configVersionID, body.Data.Relationships["configuration-version"].Data.ID)
+		})
+	}
+}
+
+func TestFindRun(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		fmt.Fprint(w, `{"data": [
+			{"id": "run-2", "type": "runs", "attributes": {"message": "terraform-k8s, apply"}},
+			{"id": "run-1", "type": "runs", "attributes": {"message": "terraform-k8s, Run nightly (uid-1)"}}
+		]}`)
+	}))
+	defer srv.Close()
+	client, err := tfe.NewClient(&tfe.Config{
+		Address:    srv.URL,
+		Token:      "token1",
+		HTTPClient: srv.Client(),
+	})
+	assert.NoError(t, err)
+
+	cloud := &TerraformCloudClient{Client: client}
+	run, err := cloud.FindRun("ws-123", "terraform-k8s, Run nightly (uid-1)")
+	assert.NoError(t, err)
+	assert.Equal(t, "run-1", run.ID)
+
+	run, err = cloud.FindRun("ws-123", "terraform-k8s, Run nightly (uid-2)")
+	assert.NoError(t, err)
+	assert.Nil(t, run)
+}
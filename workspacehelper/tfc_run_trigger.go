@@ -37,12 +37,12 @@ func (t *TerraformCloudClient) deleteRunTriggersFromTFC(specTFCRunTriggers []*tf
 }
 
 // Creates run triggers in TFC that were defined in controller spec but not created
-func (t *TerraformCloudClient) createRunTriggersOnTFC(workspace *tfc.Workspace, specTFCRunTriggers []*tfc.RunTrigger, workspaceRunTriggers []*tfc.RunTrigger) (bool, error) {
+func (t *TerraformCloudClient) createRunTriggersOnTFC(organization string, workspace *tfc.Workspace, specTFCRunTriggers []*tfc.RunTrigger, workspaceRunTriggers []*tfc.RunTrigger) (bool, error) {
 	updated := false
 	for _, rt := range specTFCRunTriggers {
 		index := findRT(workspaceRunTriggers, rt.SourceableName)
 		if index < 0 {
-			err := t.CreateTerraformRunTrigger(workspace, rt)
+			err := t.CreateTerraformRunTrigger(organization, workspace, rt)
 			if err != nil {
 				return false, err
 			}
@@ -53,22 +53,25 @@ func (t *TerraformCloudClient) createRunTriggersOnTFC(workspace *tfc.Workspace,
 	return updated, nil
 }
 
-// CheckRunTriggers deletes and update TFC run triggers as needed
-func (t *TerraformCloudClient) CheckRunTriggers(workspace string, specRunTriggers []*v1alpha1.RunTrigger) (bool, error) {
-	tfcWorkspace, err := t.Client.Workspaces.Read(context.TODO(), t.Organization, workspace)
+// CheckRunTriggers deletes and update TFC run triggers as needed.
+// Run triggers missing from the spec are only deleted when prune is set.
+func (t *TerraformCloudClient) CheckRunTriggers(organization, workspaceID string, specRunTriggers []*v1alpha1.RunTrigger, prune bool) (bool, error) {
+	tfcWorkspace, err := t.Client.Workspaces.ReadByID(context.TODO(), workspaceID)
 	if err != nil {
 		return false, err
 	}
 
-	specTFCRunTriggers := MapToTFCRunTrigger(workspace, specRunTriggers)
+	specTFCRunTriggers := MapToTFCRunTrigger(tfcWorkspace.Name, specRunTriggers)
 	workspaceRunTriggers, err := t.listRunTriggers(tfcWorkspace.ID)
 	if err != nil {
 		return false, err
 	}
-	if err := t.deleteRunTriggersFromTFC(specTFCRunTriggers, workspaceRunTriggers); err != nil {
-		return false, err
+	if prune {
+		if err := t.deleteRunTriggersFromTFC(specTFCRunTriggers, workspaceRunTriggers); err != nil {
+			return false, err
+		}
 	}
-	createdRunTriggers, err := t.createRunTriggersOnTFC(tfcWorkspace, specTFCRunTriggers, workspaceRunTriggers)
+	createdRunTriggers, err := t.createRunTriggersOnTFC(organization, tfcWorkspace, specTFCRunTriggers, workspaceRunTriggers)
 	if err != nil {
 		return false, err
 	}
@@ -103,8 +106,8 @@ func (t *TerraformCloudClient) DeleteRunTrigger(runTrigger *tfc.RunTrigger) erro
 	return nil
 }
 
-func (t *TerraformCloudClient) CreateTerraformRunTrigger(workspace *tfc.Workspace, runTrigger *tfc.RunTrigger) error {
-	tfcSourceWorkspace, err := t.Client.Workspaces.Read(context.TODO(), t.Organization, runTrigger.SourceableName)
+func (t *TerraformCloudClient) CreateTerraformRunTrigger(organization string, workspace *tfc.Workspace, runTrigger *tfc.RunTrigger) error {
+	tfcSourceWorkspace, err := t.Client.Workspaces.Read(context.TODO(), organization, runTrigger.SourceableName)
 	if err != nil {
 		return err
 	}
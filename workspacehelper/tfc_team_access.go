@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"fmt"
+
+	tfc "github.com/hashicorp/go-tfe"
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+)
+
+// validateTeamAccess checks that permissions are only set for the custom access level and that no team is listed twice
+func validateTeamAccess(teamAccess []appv1alpha1.TeamAccess) error {
+	seen := map[string]bool{}
+	for _, access := range teamAccess {
+		if seen[access.Team] {
+			return fmt.Errorf("team %q is listed more than once in teamAccess", access.Team)
+		}
+		seen[access.Team] = true
+		custom := tfc.AccessType(access.Access) == tfc.AccessCustom
+		if custom && access.Permissions == nil {
+			return fmt.Errorf("team %q has custom access without permissions", access.Team)
+		} else if !custom && access.Permissions != nil {
+			return fmt.Errorf("team %q has permissions with the %s access level, they need the custom access level",
+				access.Team, access.Access)
+		}
+	}
+	return nil
+}
+
+// resolveTeamID returns the ID of a team given by name or ID, teamIDs maps the names of the teams
+// of the organization to their IDs. Names are looked up first, as a team may be named like an ID.
+func resolveTeamID(team string, teamIDs map[string]string) (string, error) {
+	if id, ok := teamIDs[team]; ok {
+		return id, nil
+	}
+	for _, id := range teamIDs {
+		if id == team {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("team %q does not exist in the organization", team)
+}
+
+// grantedTeamAccess reports whether the operator granted a team access, access granted outside
+// of the operator is never recorded in the status
+func grantedTeamAccess(statuses []appv1alpha1.TeamAccessStatus, teamAccessID string) bool {
+	for _, status := range statuses {
+		if status.ID == teamAccessID {
+			return true
+		}
+	}
+	return false
+}
+
+// teamAccessMatches reports whether a team access grants what the spec asks for
+func teamAccessMatches(spec appv1alpha1.TeamAccess, current *tfc.TeamAccess) bool {
+	if string(current.Access) != spec.Access {
+		return false
+	}
+	if spec.Permissions == nil {
+		return true
+	}
+	p := spec.Permissions
+	return string(current.Runs) == p.Runs && string(current.Variables) == p.Variables &&
+		string(current.StateVersions) == p.StateVersions && string(current.SentinelMocks) == p.SentinelMocks &&
+		current.WorkspaceLocking == p.WorkspaceLocking
+}
+
+// teamAccessUpdateOptions are the access level and custom permissions of the spec
+func teamAccessUpdateOptions(spec appv1alpha1.TeamAccess) tfc.TeamAccessUpdateOptions {
+	access := tfc.AccessType(spec.Access)
+	options := tfc.TeamAccessUpdateOptions{Access: &access}
+	if p := spec.Permissions; p != nil {
+		runs := tfc.RunsPermissionType(p.Runs)
+		variables := tfc.VariablesPermissionType(p.Variables)
+		stateVersions := tfc.StateVersionsPermissionType(p.StateVersions)
+		sentinelMocks := tfc.SentinelMocksPermissionType(p.SentinelMocks)
+		locking := p.WorkspaceLocking
+		if p.Runs != "" {
+			options.Runs = &runs
+		}
+		if p.Variables != "" {
+			options.Variables = &variables
+		}
+		if p.StateVersions != "" {
+			options.StateVersions = &stateVersions
+		}
+		if p.SentinelMocks != "" {
+			options.SentinelMocks = &sentinelMocks
+		}
+		options.WorkspaceLocking = &locking
+	}
+	return options
+}
+
+// GetTeamIDs maps the names of the teams of the organization to their IDs
+func (t *TerraformCloudClient) GetTeamIDs(organization string) (map[string]string, error) {
+	teamIDs := map[string]string{}
+	options := tfc.TeamListOptions{ListOptions: tfc.ListOptions{PageSize: 100}}
+	for {
+		teams, err := t.Client.Teams.List(context.TODO(), organization, options)
+		if err != nil {
+			return nil, err
+		}
+		for _, team := range teams.Items {
+			teamIDs[team.Name] = team.ID
+		}
+		if teams.Pagination == nil || teams.NextPage == 0 {
+			return teamIDs, nil
+		}
+		options.PageNumber = teams.NextPage
+	}
+}
+
+// GetTeamAccess maps the team IDs with access to a workspace to their team access
+func (t *TerraformCloudClient) GetTeamAccess(workspaceID string) (map[string]*tfc.TeamAccess, error) {
+	accesses := map[string]*tfc.TeamAccess{}
+	options := tfc.TeamAccessListOptions{ListOptions: tfc.ListOptions{PageSize: 100}, WorkspaceID: &workspaceID}
+	for {
+		list, err := t.Client.TeamAccess.List(context.TODO(), options)
+		if err != nil {
+			return nil, err
+		}
+		for _, access := range list.Items {
+			if access.Team != nil {
+				accesses[access.Team.ID] = access
+			}
+		}
+		if list.Pagination == nil || list.NextPage == 0 {
+			return accesses, nil
+		}
+		options.PageNumber = list.NextPage
+	}
+}
+
+// AddTeamAccess grants a team access to a workspace
+func (t *TerraformCloudClient) AddTeamAccess(workspaceID, teamID string, spec appv1alpha1.TeamAccess) (*tfc.TeamAccess, error) {
+	update := teamAccessUpdateOptions(spec)
+	options := tfc.TeamAccessAddOptions{
+		Access:           update.Access,
+		Runs:             update.Runs,
+		Variables:        update.Variables,
+		StateVersions:    update.StateVersions,
+		SentinelMocks:    update.SentinelMocks,
+		WorkspaceLocking: update.WorkspaceLocking,
+		Team:             &tfc.Team{ID: teamID},
+		Workspace:        &tfc.Workspace{ID: workspaceID},
+	}
+	return t.Client.TeamAccess.Add(context.TODO(), options)
+}
+
+// UpdateTeamAccess changes the access level and permissions of a team access
+func (t *TerraformCloudClient) UpdateTeamAccess(teamAccessID string, spec appv1alpha1.TeamAccess) (*tfc.TeamAccess, error) {
+	return t.Client.TeamAccess.Update(context.TODO(), teamAccessID, teamAccessUpdateOptions(spec))
+}
+
+// RemoveTeamAccess revokes a team access
+func (t *TerraformCloudClient) RemoveTeamAccess(teamAccessID string) error {
+	return t.Client.TeamAccess.Remove(context.TODO(), teamAccessID)
+}
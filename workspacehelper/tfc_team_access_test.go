@@ -0,0 +1,92 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"testing"
+
+	tfc "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTeamAccess(t *testing.T) {
+	permissions := &v1alpha1.TeamPermissions{Runs: "plan"}
+	assert.NoError(t, validateTeamAccess([]v1alpha1.TeamAccess{
+		{Team: "developers", Access: "write"},
+		{Team: "team-abc123", Access: "custom", Permissions: permissions},
+	}))
+	assert.Error(t, validateTeamAccess([]v1alpha1.TeamAccess{{Team: "developers", Access: "custom"}}))
+	assert.Error(t, validateTeamAccess([]v1alpha1.TeamAccess{{Team: "developers", Access: "read", Permissions: permissions}}))
+	assert.Error(t, validateTeamAccess([]v1alpha1.TeamAccess{
+		{Team: "developers", Access: "read"},
+		{Team: "developers", Access: "write"},
+	}))
+}
+
+func TestResolveTeamID(t *testing.T) {
+	teamIDs := map[string]string{"developers": "team-dev", "team-abc123": "team-xyz789"}
+
+	id, err := resolveTeamID("team-dev", teamIDs)
+	assert.NoError(t, err)
+	assert.Equal(t, "team-dev", id)
+
+	id, err = resolveTeamID("developers", teamIDs)
+	assert.NoError(t, err)
+	assert.Equal(t, "team-dev", id)
+
+	id, err = resolveTeamID("team-abc123", teamIDs)
+	assert.NoError(t, err)
+	assert.Equal(t, "team-xyz789", id, "a team named like an ID")
+
+	_, err = resolveTeamID("team-unknown", teamIDs)
+	assert.Error(t, err, "IDs of other organizations are rejected")
+
+	_, err = resolveTeamID("operators", teamIDs)
+	assert.Error(t, err)
+}
+
+func TestGrantedTeamAccess(t *testing.T) {
+	statuses := []v1alpha1.TeamAccessStatus{{Team: "developers", TeamID: "team-dev", ID: "tws-1", Access: "write"}}
+	assert.True(t, grantedTeamAccess(statuses, "tws-1"))
+	assert.False(t, grantedTeamAccess(statuses, "tws-2"))
+	assert.False(t, grantedTeamAccess(nil, "tws-1"))
+}
+
+func TestTeamAccessMatches(t *testing.T) {
+	current := &tfc.TeamAccess{
+		Access:        tfc.AccessCustom,
+		Runs:          tfc.RunsPermissionPlan,
+		Variables:     tfc.VariablesPermissionRead,
+		StateVersions: tfc.StateVersionsPermissionReadOutputs,
+		SentinelMocks: tfc.SentinelMocksPermissionNone,
+	}
+	spec := v1alpha1.TeamAccess{Team: "developers", Access: "custom", Permissions: &v1alpha1.TeamPermissions{
+		Runs:          "plan",
+		Variables:     "read",
+		StateVersions: "read-outputs",
+		SentinelMocks: "none",
+	}}
+	assert.True(t, teamAccessMatches(spec, current))
+
+	spec.Permissions.WorkspaceLocking = true
+	assert.False(t, teamAccessMatches(spec, current))
+
+	assert.False(t, teamAccessMatches(v1alpha1.TeamAccess{Team: "developers", Access: "write"}, current))
+	assert.True(t, teamAccessMatches(v1alpha1.TeamAccess{Team: "developers", Access: "write"},
+		&tfc.TeamAccess{Access: tfc.AccessWrite, Runs: tfc.RunsPermissionApply}))
+}
+
+func TestTeamAccessUpdateOptions(t *testing.T) {
+	options := teamAccessUpdateOptions(v1alpha1.TeamAccess{Team: "developers", Access: "admin"})
+	assert.Equal(t, tfc.AccessAdmin, *options.Access)
+	assert.Nil(t, options.Runs)
+	assert.Nil(t, options.WorkspaceLocking)
+
+	options = teamAccessUpdateOptions(v1alpha1.TeamAccess{Team: "developers", Access: "custom",
+		Permissions: &v1alpha1.TeamPermissions{Runs: "apply", WorkspaceLocking: true}})
+	assert.Equal(t, tfc.RunsPermissionApply, *options.Runs)
+	assert.Nil(t, options.Variables)
+	assert.True(t, *options.WorkspaceLocking)
+}
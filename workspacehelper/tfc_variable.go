@@ -42,9 +42,9 @@ func MapToTFCVariable(specVariables []*v1alpha1.Variable) []*tfc.Variable {
 }
 
 // CheckSecretsMountPath ensure the secrets mount path actually exists
-func (t *TerraformCloudClient) CheckSecretsMountPath() error {
-	if _, err := os.Stat(t.SecretsMountPath); os.IsNotExist(err) {
-		return fmt.Errorf("Secrets Mount Path is invalid: %s", t.SecretsMountPath)
+func CheckSecretsMountPath(secretsMountPath string) error {
+	if _, err := os.Stat(secretsMountPath); os.IsNotExist(err) {
+		return fmt.Errorf("Secrets Mount Path is invalid: %s", secretsMountPath)
 	}
 	return nil
 }
@@ -62,12 +62,12 @@ func (t *TerraformCloudClient) deleteVariablesFromTFC(specTFCVariables []*tfc.Va
 	return nil
 }
 
-func (t *TerraformCloudClient) createVariablesOnTFC(workspace *tfc.Workspace, specTFCVariables []*tfc.Variable, workspaceVariables []*tfc.Variable) (bool, error) {
+func (t *TerraformCloudClient) createVariablesOnTFC(workspace *tfc.Workspace, specTFCVariables []*tfc.Variable, workspaceVariables []*tfc.Variable, secretsMountPath string) (bool, error) {
 	updated := false
 	for _, v := range specTFCVariables {
 		index := find(workspaceVariables, v.Key)
 		if index < 0 {
-			err := t.CreateTerraformVariable(workspace, v)
+			err := t.CreateTerraformVariable(workspace, v, secretsMountPath)
 			if err != nil {
 				return false, err
 			}
@@ -145,9 +145,11 @@ func generateUpdateVariableList(specTFCVariables []*tfc.Variable, workspaceVaria
 	return updateList, nil
 }
 
-// CheckVariables creates, updates, or deletes variables as needed
-func (t *TerraformCloudClient) CheckVariables(workspace string, specTFCVariables []*tfc.Variable) (bool, error) {
-	tfcWorkspace, err := t.Client.Workspaces.Read(context.TODO(), t.Organization, workspace)
+// CheckVariables creates, updates, or deletes variables as needed.
+// Variables missing from the spec are only deleted when prune is set.
+// Sensitive variables without a value are read from secretsMountPath.
+func (t *TerraformCloudClient) CheckVariables(workspaceID string, specTFCVariables []*tfc.Variable, prune bool, secretsMountPath string) (bool, error) {
+	tfcWorkspace, err := t.Client.Workspaces.ReadByID(context.TODO(), workspaceID)
 	if err != nil {
 		return false, err
 	}
@@ -155,16 +157,18 @@ func (t *TerraformCloudClient) CheckVariables(workspace string, specTFCVariables
 	if err != nil {
 		return false, err
 	}
-	if err := t.deleteVariablesFromTFC(specTFCVariables, workspaceVariables); err != nil {
-		return false, err
+	if prune {
+		if err := t.deleteVariablesFromTFC(specTFCVariables, workspaceVariables); err != nil {
+			return false, err
+		}
 	}
 
-	createdVariables, err := t.createVariablesOnTFC(tfcWorkspace, specTFCVariables, workspaceVariables)
+	createdVariables, err := t.createVariablesOnTFC(tfcWorkspace, specTFCVariables, workspaceVariables, secretsMountPath)
 	if err != nil {
 		return false, err
 	}
 
-	variablesToUpdate, err := generateUpdateVariableList(specTFCVariables, workspaceVariables, t.SecretsMountPath)
+	variablesToUpdate, err := generateUpdateVariableList(specTFCVariables, workspaceVariables, secretsMountPath)
 	if err != nil || len(variablesToUpdate) == 0 {
 		return false, err
 	}
@@ -242,8 +246,8 @@ func checkAndRetrieveIfSensitive(variable *tfc.Variable, secretsMountPath string
 }
 
 // CreateTerraformVariable creates a Terraform variable based on key and value
-func (t *TerraformCloudClient) CreateTerraformVariable(workspace *tfc.Workspace, variable *tfc.Variable) error {
-	if err := checkAndRetrieveIfSensitive(variable, t.SecretsMountPath); err != nil {
+func (t *TerraformCloudClient) CreateTerraformVariable(workspace *tfc.Workspace, variable *tfc.Variable, secretsMountPath string) error {
+	if err := checkAndRetrieveIfSensitive(variable, secretsMountPath); err != nil {
 		return err
 	}
 	options := tfc.VariableCreateOptions{
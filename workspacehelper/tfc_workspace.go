@@ -0,0 +1,255 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	tfc "github.com/hashicorp/go-tfe"
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+)
+
+// declaresAgentPool reports whether the spec selects an agent pool by ID or name
+func declaresAgentPool(instance *appv1alpha1.Workspace) bool {
+	return instance.Spec.AgentPoolID != "" || instance.Spec.AgentPoolName != ""
+}
+
+// validateExecutionMode checks that the execution mode agrees with the agent pool of the spec
+func validateExecutionMode(instance *appv1alpha1.Workspace) error {
+	switch mode := instance.Spec.ExecutionMode; {
+	case mode == "":
+		return nil
+	case mode == appv1alpha1.ExecutionModeAgent && !declaresAgentPool(instance):
+		return fmt.Errorf("execution mode %s needs an agentPoolID or agentPoolName", mode)
+	case mode != appv1alpha1.ExecutionModeAgent && declaresAgentPool(instance):
+		return fmt.Errorf("execution mode %s cannot be combined with an agent pool", mode)
+	}
+	return nil
+}
+
+// validateTagsRegex checks that tag triggers are not combined with file triggers, which Terraform
+// Cloud uses instead of tags
+func validateTagsRegex(instance *appv1alpha1.Workspace) error {
+	vcs := instance.Spec.VCS
+	if vcs == nil || vcs.TagsRegex == "" {
+		return nil
+	}
+	if len(vcs.TriggerPrefixes) > 0 {
+		return fmt.Errorf("vcs.tags_regex cannot be combined with vcs.trigger_prefixes")
+	}
+	if enabled := instance.Spec.FileTriggersEnabled; enabled != nil && *enabled {
+		return fmt.Errorf("vcs.tags_regex cannot be combined with fileTriggersEnabled")
+	}
+	return nil
+}
+
+// workspaceSettings returns an update for the settings of the spec that differ from the workspace,
+// and false when they all match. Settings that are not set in the spec are left alone.
+func workspaceSettings(instance *appv1alpha1.Workspace, ws *tfc.Workspace) (tfc.WorkspaceUpdateOptions, bool) {
+	options := tfc.WorkspaceUpdateOptions{}
+	changed := false
+	updateBool := func(desired *bool, current bool) *bool {
+		if desired == nil || *desired == current {
+			return nil
+		}
+		changed = true
+		return desired
+	}
+
+	spec := instance.Spec
+	if spec.Description != nil && *spec.Description != ws.Description {
+		options.Description = spec.Description
+		changed = true
+	}
+	// The agent execution mode is set together with the agent pool
+	if mode := spec.ExecutionMode; mode != "" && mode != appv1alpha1.ExecutionModeAgent && string(mode) != ws.ExecutionMode {
+		options.ExecutionMode = tfc.String(string(mode))
+		changed = true
+	}
+	options.QueueAllRuns = updateBool(spec.QueueAllRuns, ws.QueueAllRuns)
+	options.SpeculativeEnabled = updateBool(spec.SpeculativeEnabled, ws.SpeculativeEnabled)
+	options.AllowDestroyPlan = updateBool(spec.AllowDestroyPlan, ws.AllowDestroyPlan)
+	options.GlobalRemoteState = updateBool(spec.GlobalRemoteState, ws.GlobalRemoteState)
+	options.FileTriggersEnabled = updateBool(spec.FileTriggersEnabled, ws.FileTriggersEnabled)
+	return options, changed
+}
+
+// createWorkspaceSettings sets the settings of the spec on the options used to create a workspace
+func createWorkspaceSettings(instance *appv1alpha1.Workspace, options *tfc.WorkspaceCreateOptions) {
+	spec := instance.Spec
+	options.Description = spec.Description
+	if spec.ExecutionMode != "" && spec.ExecutionMode != appv1alpha1.ExecutionModeAgent {
+		options.ExecutionMode = tfc.String(string(spec.ExecutionMode))
+	}
+	options.QueueAllRuns = spec.QueueAllRuns
+	options.SpeculativeEnabled = spec.SpeculativeEnabled
+	options.AllowDestroyPlan = spec.AllowDestroyPlan
+	options.GlobalRemoteState = spec.GlobalRemoteState
+	options.FileTriggersEnabled = spec.FileTriggersEnabled
+	if spec.SourceName != "" {
+		options.SourceName = &spec.SourceName
+	}
+	if spec.SourceURL != "" {
+		options.SourceURL = &spec.SourceURL
+	}
+}
+
+// UpdateWorkspaceSettings updates the settings of the workspace that differ from the spec
+func (t *TerraformCloudClient) UpdateWorkspaceSettings(instance *appv1alpha1.Workspace, ws *tfc.Workspace) error {
+	options, changed := workspaceSettings(instance, ws)
+	if !changed {
+		return nil
+	}
+	_, err := t.Client.Workspaces.UpdateByID(context.TODO(), ws.ID, options)
+	return err
+}
+
+// vcsRepoOptions are the options connecting a workspace to the VCS repository of the spec
+func vcsRepoOptions(vcs *appv1alpha1.VCS) *tfc.VCSRepoOptions {
+	return &tfc.VCSRepoOptions{
+		Branch:            tfc.String(vcs.Branch),
+		Identifier:        tfc.String(vcs.RepoIdentifier),
+		IngressSubmodules: tfc.Bool(vcs.IngressSubmodules),
+		OAuthTokenID:      tfc.String(vcs.TokenID),
+	}
+}
+
+// vcsSettings returns an update connecting the workspace to the VCS repository of the spec,
+// and false when the repository, working directory and trigger prefixes already match
+func vcsSettings(vcs *appv1alpha1.VCS, ws *tfc.Workspace) (tfc.WorkspaceUpdateOptions, bool) {
+	options := tfc.WorkspaceUpdateOptions{}
+	changed := false
+	repo := ws.VCSRepo
+	if repo == nil || repo.Identifier != vcs.RepoIdentifier || repo.Branch != vcs.Branch ||
+		repo.OAuthTokenID != vcs.TokenID || repo.IngressSubmodules != vcs.IngressSubmodules {
+		options.VCSRepo = vcsRepoOptions(vcs)
+		changed = true
+	}
+	if vcs.WorkingDirectory != ws.WorkingDirectory {
+		options.WorkingDirectory = tfc.String(vcs.WorkingDirectory)
+		changed = true
+	}
+	// Empty trigger prefixes are left out of the request, so they are only managed when set
+	if len(vcs.TriggerPrefixes) > 0 && !reflect.DeepEqual(vcs.TriggerPrefixes, ws.TriggerPrefixes) {
+		options.TriggerPrefixes = vcs.TriggerPrefixes
+		changed = true
+	}
+	return options, changed
+}
+
+// UpdateVCSRepo connects the workspace to the VCS repository of the spec, and reports whether it changed
+func (t *TerraformCloudClient) UpdateVCSRepo(vcs *appv1alpha1.VCS, ws *tfc.Workspace) (bool, error) {
+	options, changed := vcsSettings(vcs, ws)
+	if !changed {
+		return false, nil
+	}
+	if _, err := t.Client.Workspaces.UpdateByID(context.TODO(), ws.ID, options); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetTagsRegex reads the regular expression of the Git tags that trigger runs of a VCS workspace
+func (t *TerraformCloudClient) GetTagsRegex(workspaceID string) (string, error) {
+	var workspace struct {
+		Data struct {
+			Attributes struct {
+				VCSRepo *struct {
+					TagsRegex string `json:"tags-regex"`
+				} `json:"vcs-repo"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := t.apiRequest(http.MethodGet, "workspaces/"+url.QueryEscape(workspaceID), nil, &workspace); err != nil {
+		return "", err
+	}
+	if workspace.Data.Attributes.VCSRepo == nil {
+		return "", nil
+	}
+	return workspace.Data.Attributes.VCSRepo.TagsRegex, nil
+}
+
+// UpdateTagsRegex makes pushes of Git tags matching the regular expression trigger runs of a VCS
+// workspace, instead of file triggers
+func (t *TerraformCloudClient) UpdateTagsRegex(workspaceID string, vcs *appv1alpha1.VCS) error {
+	body := map[string]interface{}{
+		"data": map[string]interface{}{
+			"type": "workspaces",
+			"attributes": map[string]interface{}{
+				"file-triggers-enabled": false,
+				"vcs-repo": map[string]interface{}{
+					"identifier":         vcs.RepoIdentifier,
+					"oauth-token-id":     vcs.TokenID,
+					"branch":             vcs.Branch,
+					"ingress-submodules": vcs.IngressSubmodules,
+					"tags-regex":         vcs.TagsRegex,
+				},
+			},
+		},
+	}
+	return t.apiRequest(http.MethodPatch, "workspaces/"+url.QueryEscape(workspaceID), body, nil)
+}
+
+// vcsConfigurationReady reports whether the latest configuration version was ingressed from the VCS
+// repository of the spec, so a run does not pick up a module upload or the previous repository
+func vcsConfigurationReady(vcs *appv1alpha1.VCS, cv *tfc.ConfigurationVersion) bool {
+	if cv.Source == tfc.ConfigurationSourceAPI || cv.Status != tfc.ConfigurationUploaded {
+		return false
+	}
+	ingress := cv.IngressAttributes
+	if ingress == nil || ingress.Identifier == "" {
+		// Not every Terraform Enterprise version reports where the configuration came from
+		return true
+	}
+	if !strings.EqualFold(ingress.Identifier, vcs.RepoIdentifier) {
+		return false
+	}
+	return vcs.Branch == "" || ingress.Branch == vcs.Branch
+}
+
+// GetWorkspaceTags lists the names of the tags of a workspace
+func (t *TerraformCloudClient) GetWorkspaceTags(workspaceID string) ([]string, error) {
+	tags := []string{}
+	options := tfc.WorkspaceTagListOptions{ListOptions: tfc.ListOptions{PageSize: 100}}
+	for {
+		list, err := t.Client.Workspaces.Tags(context.TODO(), workspaceID, options)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range list.Items {
+			tags = append(tags, tag.Name)
+		}
+		if list.Pagination == nil || list.NextPage == 0 {
+			return tags, nil
+		}
+		options.PageNumber = list.NextPage
+	}
+}
+
+// UpdateWorkspaceTags adds and removes tags of a workspace by name, creating organization tags as needed
+func (t *TerraformCloudClient) UpdateWorkspaceTags(workspaceID string, add, remove []string) error {
+	toTags := func(names []string) []*tfc.Tag {
+		tags := []*tfc.Tag{}
+		for _, name := range names {
+			tags = append(tags, &tfc.Tag{Name: name})
+		}
+		return tags
+	}
+	if len(add) > 0 {
+		if err := t.Client.Workspaces.AddTags(context.TODO(), workspaceID, tfc.WorkspaceAddTagsOptions{Tags: toTags(add)}); err != nil {
+			return err
+		}
+	}
+	if len(remove) > 0 {
+		if err := t.Client.Workspaces.RemoveTags(context.TODO(), workspaceID, tfc.WorkspaceRemoveTagsOptions{Tags: toTags(remove)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tfc "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateExecutionMode(t *testing.T) {
+	workspace := &v1alpha1.Workspace{}
+	assert.NoError(t, validateExecutionMode(workspace))
+
+	workspace.Spec.ExecutionMode = v1alpha1.ExecutionModeAgent
+	assert.Error(t, validateExecutionMode(workspace), "agent execution without a pool")
+
+	workspace.Spec.AgentPoolName = "pool"
+	assert.NoError(t, validateExecutionMode(workspace))
+
+	workspace.Spec.ExecutionMode = v1alpha1.ExecutionModeLocal
+	assert.Error(t, validateExecutionMode(workspace), "local execution with a pool")
+}
+
+func TestWorkspaceSettings(t *testing.T) {
+	ws := &tfc.Workspace{
+		ID:                 "ws-123",
+		Description:        "greetings",
+		ExecutionMode:      "remote",
+		QueueAllRuns:       false,
+		SpeculativeEnabled: true,
+		AllowDestroyPlan:   true,
+	}
+
+	workspace := &v1alpha1.Workspace{}
+	_, changed := workspaceSettings(workspace, ws)
+	assert.False(t, changed, "nothing declared in the spec")
+
+	workspace.Spec.Description = tfc.String("greetings")
+	workspace.Spec.ExecutionMode = v1alpha1.ExecutionModeRemote
+	workspace.Spec.SpeculativeEnabled = tfc.Bool(true)
+	_, changed = workspaceSettings(workspace, ws)
+	assert.False(t, changed, "declared settings match")
+
+	workspace.Spec.Description = tfc.String("hello")
+	workspace.Spec.QueueAllRuns = tfc.Bool(true)
+	workspace.Spec.AllowDestroyPlan = tfc.Bool(false)
+	options, changed := workspaceSettings(workspace, ws)
+	assert.True(t, changed)
+	assert.Equal(t, "hello", *options.Description)
+	assert.True(t, *options.QueueAllRuns)
+	assert.False(t, *options.AllowDestroyPlan)
+	assert.Nil(t, options.ExecutionMode)
+	assert.Nil(t, options.SpeculativeEnabled)
+	assert.Nil(t, options.GlobalRemoteState)
+
+	workspace = &v1alpha1.Workspace{}
+	workspace.Spec.ExecutionMode = v1alpha1.ExecutionModeAgent
+	workspace.Spec.AgentPoolID = "apool-123"
+	_, changed = workspaceSettings(workspace, ws)
+	assert.False(t, changed, "agent execution is set with the agent pool")
+}
+
+func TestCreateWorkspaceSettings(t *testing.T) {
+	workspace := &v1alpha1.Workspace{}
+	workspace.Spec.Description = tfc.String("greetings")
+	workspace.Spec.ExecutionMode = v1alpha1.ExecutionModeLocal
+	workspace.Spec.GlobalRemoteState = tfc.Bool(true)
+	workspace.Spec.SourceName = "terraform-k8s"
+
+	options := tfc.WorkspaceCreateOptions{}
+	createWorkspaceSettings(workspace, &options)
+	assert.Equal(t, "greetings", *options.Description)
+	assert.Equal(t, "local", *options.ExecutionMode)
+	assert.True(t, *options.GlobalRemoteState)
+	assert.Equal(t, "terraform-k8s", *options.SourceName)
+	assert.Nil(t, options.SourceURL)
+	assert.Nil(t, options.QueueAllRuns)
+}
+
+func TestVCSSettings(t *testing.T) {
+	vcs := &v1alpha1.VCS{
+		TokenID:          "ot-123",
+		RepoIdentifier:   "org/monorepo",
+		Branch:           "main",
+		WorkingDirectory: "services/greetings",
+	}
+	ws := &tfc.Workspace{
+		VCSRepo: &tfc.VCSRepo{
+			Identifier:   "org/monorepo",
+			Branch:       "main",
+			OAuthTokenID: "ot-123",
+		},
+		WorkingDirectory: "services/greetings",
+	}
+	_, changed := vcsSettings(vcs, ws)
+	assert.False(t, changed)
+
+	vcs.Branch = "release"
+	vcs.TriggerPrefixes = []string{"modules/"}
+	options, changed := vcsSettings(vcs, ws)
+	assert.True(t, changed)
+	assert.Equal(t, "release", *options.VCSRepo.Branch)
+	assert.Equal(t, "org/monorepo", *options.VCSRepo.Identifier)
+	assert.Equal(t, []string{"modules/"}, options.TriggerPrefixes)
+	assert.Nil(t, options.WorkingDirectory)
+
+	options, changed = vcsSettings(vcs, &tfc.Workspace{})
+	assert.True(t, changed, "connect a module backed workspace")
+	assert.NotNil(t, options.VCSRepo)
+	assert.Equal(t, "services/greetings", *options.WorkingDirectory)
+}
+
+func TestVCSConfigurationReady(t *testing.T) {
+	vcs := &v1alpha1.VCS{RepoIdentifier: "org/monorepo", Branch: "main"}
+	tests := []struct {
+		name string
+		cv   *tfc.ConfigurationVersion
+		want bool
+	}{
+		{"Module upload", &tfc.ConfigurationVersion{Source: tfc.ConfigurationSourceAPI, Status: tfc.ConfigurationUploaded}, false},
+		{"Still ingressing", &tfc.ConfigurationVersion{Source: tfc.ConfigurationSourceGithub, Status: tfc.ConfigurationPending}, false},
+		{"Previous repository", &tfc.ConfigurationVersion{Source: tfc.ConfigurationSourceGithub, Status: tfc.ConfigurationUploaded,
+			IngressAttributes: &tfc.IngressAttributes{Identifier: "org/other", Branch: "main"}}, false},
+		{"Previous branch", &tfc.ConfigurationVersion{Source: tfc.ConfigurationSourceGithub, Status: tfc.ConfigurationUploaded,
+			IngressAttributes: &tfc.IngressAttributes{Identifier: "org/monorepo", Branch: "develop"}}, false},
+		{"Ingressed", &tfc.ConfigurationVersion{Source: tfc.ConfigurationSourceGithub, Status: tfc.ConfigurationUploaded,
+			IngressAttributes: &tfc.IngressAttributes{Identifier: "Org/Monorepo", Branch: "main"}}, true},
+		{"No ingress attributes", &tfc.ConfigurationVersion{Source: tfc.ConfigurationSourceGitlab, Status: tfc.ConfigurationUploaded}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, vcsConfigurationReady(vcs, tt.cv))
+		})
+	}
+}
+
+func TestValidateTagsRegex(t *testing.T) {
+	workspace := &v1alpha1.Workspace{}
+	assert.NoError(t, validateTagsRegex(workspace))
+
+	workspace.Spec.VCS = &v1alpha1.VCS{RepoIdentifier: "org/monorepo", TagsRegex: `\d+.\d+.\d+$`}
+	assert.NoError(t, validateTagsRegex(workspace))
+
+	workspace.Spec.FileTriggersEnabled = tfc.Bool(false)
+	assert.NoError(t, validateTagsRegex(workspace))
+
+	workspace.Spec.FileTriggersEnabled = tfc.Bool(true)
+	assert.Error(t, validateTagsRegex(workspace), "tags with file triggers")
+
+	workspace.Spec.FileTriggersEnabled = nil
+	workspace.Spec.VCS.TriggerPrefixes = []string{"modules/"}
+	assert.Error(t, validateTagsRegex(workspace), "tags with trigger prefixes")
+}
+
+func TestTagsRegex(t *testing.T) {
+	var patched map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v2/workspaces/ws-123", r.URL.Path)
+		assert.Equal(t, "Bearer token1", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.Method == http.MethodPatch {
+			var body struct {
+				Data struct {
+					Attributes map[string]interface{} `json:"attributes"`
+				} `json:"data"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			patched = body.Data.Attributes
+		}
+		fmt.Fprint(w, `{"data": {"id": "ws-123", "type": "workspaces",
+			"attributes": {"vcs-repo": {"identifier": "org/monorepo", "tags-regex": "v.*"}}}}`)
+	}))
+	defer srv.Close()
+
+	cloud := &TerraformCloudClient{Config: &tfc.Config{Address: srv.URL, Token: "token1", HTTPClient: srv.Client()}}
+	tagsRegex, err := cloud.GetTagsRegex("ws-123")
+	assert.NoError(t, err)
+	assert.Equal(t, "v.*", tagsRegex)
+
+	vcs := &v1alpha1.VCS{TokenID: "ot-123", RepoIdentifier: "org/monorepo", TagsRegex: "release-.*"}
+	assert.NoError(t, cloud.UpdateTagsRegex("ws-123", vcs))
+	assert.Equal(t, false, patched["file-triggers-enabled"])
+	assert.Equal(t, map[string]interface{}{
+		"identifier":         "org/monorepo",
+		"oauth-token-id":     "ot-123",
+		"branch":             "",
+		"ingress-submodules": false,
+		"tags-regex":         "release-.*",
+	}, patched["vcs-repo"])
+}
@@ -0,0 +1,149 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// awaitingApproval reports whether the current run is parked until someone approves or discards it
+func awaitingApproval(instance *appv1alpha1.Workspace) bool {
+	if !isConfirmable(instance.Status.RunStatus) || costRejected(instance) {
+		return false
+	}
+	return !isAutoApply(instance) || costHeld(instance)
+}
+
+// approvalGate applies or discards a run held for approval when the object that started it, a
+// Workspace or a Run, is annotated with the ID of that exact run
+type approvalGate struct {
+	client    client.Client
+	tfclient  *TerraformCloudClient
+	recorder  record.EventRecorder
+	reqLogger logr.Logger
+	// object carries the annotations, and is the object events are recorded for
+	object client.Object
+	// kind of object, as named in the events
+	kind string
+	// state is the approval state in the status of object
+	state *string
+}
+
+func (r *WorkspaceHelper) approvalGate(instance *appv1alpha1.Workspace) *approvalGate {
+	return &approvalGate{
+		client:    r.client,
+		tfclient:  r.tfclient,
+		recorder:  r.recorder,
+		reqLogger: r.reqLogger,
+		object:    instance,
+		kind:      "Workspace",
+		state:     &instance.Status.ApprovalState,
+	}
+}
+
+func (g *approvalGate) event(eventType, message string) {
+	g.recorder.Event(g.object, eventType, g.kind+"Event", message)
+}
+
+func (g *approvalGate) removeAnnotations() error {
+	patched := g.object.DeepCopyObject().(client.Object)
+	annotations := patched.GetAnnotations()
+	delete(annotations, appv1alpha1.ApproveRunAnnotation)
+	delete(annotations, appv1alpha1.DiscardRunAnnotation)
+	patched.SetAnnotations(annotations)
+	if err := g.client.Patch(context.TODO(), patched, client.MergeFrom(g.object)); err != nil {
+		g.reqLogger.Error(err, "Failed to remove approval annotations")
+		return err
+	}
+	g.object.SetAnnotations(patched.GetAnnotations())
+	g.object.SetResourceVersion(patched.GetResourceVersion())
+	g.object.SetManagedFields(patched.GetManagedFields())
+	return nil
+}
+
+func (g *approvalGate) setState(state string) error {
+	if *g.state == state {
+		return nil
+	}
+	*g.state = state
+	if err := g.client.Status().Update(context.TODO(), g.object); err != nil {
+		g.reqLogger.Error(err, "Failed to update approval state")
+		return err
+	}
+	return nil
+}
+
+// reconcile applies or discards the run waiting for approval once the object is annotated with
+// its ID. Until then the run needs approval, which detail explains in the event asking for it.
+func (g *approvalGate) reconcile(runID, detail string) error {
+	annotations := g.object.GetAnnotations()
+	approveID := annotations[appv1alpha1.ApproveRunAnnotation]
+	discardID := annotations[appv1alpha1.DiscardRunAnnotation]
+
+	if approveID == "" && discardID == "" {
+		if *g.state != appv1alpha1.ApprovalStateNeedsApproval {
+			g.event(corev1.EventTypeNormal,
+				fmt.Sprintf("Run %s%s needs approval, annotate the %s with %s=%s to apply it or %s=%s to discard it",
+					runID, detail, g.kind, appv1alpha1.ApproveRunAnnotation, runID, appv1alpha1.DiscardRunAnnotation, runID))
+		}
+		return g.setState(appv1alpha1.ApprovalStateNeedsApproval)
+	}
+
+	if approveID != "" && discardID != "" {
+		g.event(corev1.EventTypeWarning,
+			fmt.Sprintf("Ignoring conflicting approval annotations, run %s is both approved and discarded", runID))
+		return g.removeAnnotations()
+	}
+
+	if requestedID := approveID + discardID; requestedID != runID {
+		g.event(corev1.EventTypeWarning,
+			fmt.Sprintf("Ignoring approval annotation for run %s, run %s is the one waiting for approval",
+				requestedID, runID))
+		return g.removeAnnotations()
+	}
+
+	var state, msg string
+	if discardID != "" {
+		comment := fmt.Sprintf("%s, discarded via %s annotation", TerraformOperator, appv1alpha1.DiscardRunAnnotation)
+		if err := g.tfclient.DiscardRun(runID, comment); err != nil {
+			g.reqLogger.Error(err, "Could not discard run", "RunID", runID)
+			return err
+		}
+		state = appv1alpha1.ApprovalStateDiscarded
+		msg = fmt.Sprintf("Run %s discarded", runID)
+	} else {
+		comment := fmt.Sprintf("%s, approved via %s annotation", TerraformOperator, appv1alpha1.ApproveRunAnnotation)
+		if err := g.tfclient.ApplyRun(runID, comment); err != nil {
+			g.reqLogger.Error(err, "Could not apply run", "RunID", runID)
+			return err
+		}
+		state = appv1alpha1.ApprovalStateApproved
+		msg = fmt.Sprintf("Run %s approved and queued for apply", runID)
+	}
+	g.event(corev1.EventTypeNormal, msg)
+	g.reqLogger.Info("Processed run approval", g.kind, g.object.GetName(), "RunID", runID, "ApprovalState", state)
+
+	if err := g.removeAnnotations(); err != nil {
+		return err
+	}
+	return g.setState(state)
+}
+
+// reconcileApproval applies or discards a run waiting for approval when the
+// Workspace is annotated with the ID of that exact run.
+func (r *WorkspaceHelper) reconcileApproval(instance *appv1alpha1.Workspace) error {
+	runID := instance.Status.RunID
+	changes := ""
+	if instance.Status.Changes != nil && instance.Status.Changes.RunID == runID {
+		changes = fmt.Sprintf(" (%s)", instance.Status.Changes.Summary)
+	}
+	return r.approvalGate(instance).reconcile(runID, changes)
+}
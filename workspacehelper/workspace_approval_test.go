@@ -0,0 +1,43 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldAwaitApprovalOnlyInManualMode(t *testing.T) {
+	workspace := &v1alpha1.Workspace{
+		Status: v1alpha1.WorkspaceStatus{RunStatus: "planned"},
+	}
+	assert.False(t, awaitingApproval(workspace))
+
+	workspace.Spec.ApplyMode = v1alpha1.ApplyModeAuto
+	assert.False(t, awaitingApproval(workspace))
+
+	workspace.Spec.ApplyMode = v1alpha1.ApplyModeManual
+	assert.True(t, awaitingApproval(workspace))
+}
+
+func TestShouldAwaitApprovalWhileRunIsConfirmable(t *testing.T) {
+	workspace := &v1alpha1.Workspace{
+		Spec: v1alpha1.WorkspaceSpec{ApplyMode: v1alpha1.ApplyModeManual},
+	}
+	for status, expected := range map[string]bool{
+		"planning":             false,
+		"planned":              true,
+		"cost_estimated":       true,
+		"policy_checked":       true,
+		"applying":             false,
+		"applied":              false,
+		"planned_and_finished": false,
+		"discarded":            false,
+	} {
+		workspace.Status.RunStatus = status
+		assert.Equal(t, expected, awaitingApproval(workspace), status)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"fmt"
+
+	tfc "github.com/hashicorp/go-tfe"
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// changesOutdated reports whether the resource changes in the status need to be read again for the current run
+func changesOutdated(instance *appv1alpha1.Workspace) bool {
+	status := instance.Status.RunStatus
+	if instance.Status.RunID == "" || (isPending(status) && !isConfirmable(status)) {
+		// Nothing was planned yet
+		return false
+	}
+	changes := instance.Status.Changes
+	if changes == nil || changes.RunID != instance.Status.RunID {
+		return true
+	}
+	return tfc.RunStatus(status) == tfc.RunApplied && changes.Apply == nil
+}
+
+// updateRunChanges records the resource changes of the current run once it is planned and again once it is applied.
+// The changes are only informational, so failing to read them is reported in the ChangesSynced condition and
+// retried on the next reconcile instead of holding up the outputs and approvals.
+func (r *WorkspaceHelper) updateRunChanges(instance *appv1alpha1.Workspace) error {
+	if !changesOutdated(instance) {
+		return nil
+	}
+
+	applied := tfc.RunStatus(instance.Status.RunStatus) == tfc.RunApplied
+	changes, err := r.tfclient.GetRunChanges(instance.Status.RunID, applied)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not get resource changes of run", "RunID", instance.Status.RunID)
+		setCondition(instance, appv1alpha1.ConditionChangesSynced, metav1.ConditionFalse, reasonChangesSyncFailed,
+			fmt.Sprintf("Could not read the resource changes of run %s: %s", instance.Status.RunID, err))
+		return nil
+	}
+	instance.Status.Changes = changes
+	setCondition(instance, appv1alpha1.ConditionChangesSynced, metav1.ConditionTrue, reasonChangesSynced,
+		fmt.Sprintf("Run %s %s", instance.Status.RunID, changes.Summary))
+	if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+		r.reqLogger.Error(err, "Failed to update resource changes")
+		return err
+	}
+
+	verb := "planned"
+	if applied {
+		verb = "applied"
+	}
+	r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent",
+		fmt.Sprintf("Run %s %s %s", instance.Status.RunID, verb, changes.Summary))
+	return nil
+}
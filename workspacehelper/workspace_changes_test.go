@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestChangesOutdated(t *testing.T) {
+	workspace := &v1alpha1.Workspace{
+		Status: v1alpha1.WorkspaceStatus{RunID: "run-2", RunStatus: "planning"},
+	}
+	assert.False(t, changesOutdated(workspace), "not planned yet")
+
+	workspace.Status.RunStatus = "planned"
+	assert.True(t, changesOutdated(workspace), "no changes recorded")
+
+	workspace.Status.Changes = &v1alpha1.RunChanges{RunID: "run-1"}
+	assert.True(t, changesOutdated(workspace), "changes of a previous run")
+
+	workspace.Status.Changes = &v1alpha1.RunChanges{RunID: "run-2"}
+	assert.False(t, changesOutdated(workspace), "plan changes recorded")
+
+	workspace.Status.RunStatus = "applying"
+	assert.False(t, changesOutdated(workspace), "apply in progress")
+
+	workspace.Status.RunStatus = "applied"
+	assert.True(t, changesOutdated(workspace), "apply changes missing")
+
+	workspace.Status.Changes.Apply = &v1alpha1.ResourceCounts{}
+	assert.False(t, changesOutdated(workspace), "apply changes recorded")
+}
+
+func TestUnreadableChangesDoNotFailTheReconcile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.URL.Path == "/api/v2/ping" {
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	client, err := tfe.NewClient(&tfe.Config{
+		Address:    srv.URL,
+		Token:      "token1",
+		HTTPClient: srv.Client(),
+	})
+	assert.NoError(t, err)
+
+	r := &WorkspaceHelper{
+		tfclient:  &TerraformCloudClient{Client: client},
+		reqLogger: log,
+	}
+	workspace := &v1alpha1.Workspace{
+		Status: v1alpha1.WorkspaceStatus{RunID: "run-2", RunStatus: "applied"},
+	}
+	assert.NoError(t, r.updateRunChanges(workspace))
+	assert.Nil(t, workspace.Status.Changes)
+	condition := meta.FindStatusCondition(workspace.Status.Conditions, v1alpha1.ConditionChangesSynced)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, reasonChangesSyncFailed, condition.Reason)
+}
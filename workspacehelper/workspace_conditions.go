@@ -0,0 +1,169 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"fmt"
+
+	tfc "github.com/hashicorp/go-tfe"
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition reasons set by the reconcile phases
+const (
+	reasonReconciled              = "Reconciled"
+	reasonInitializationFailed    = "InitializationFailed"
+	reasonWorkspaceSyncFailed     = "WorkspaceSyncFailed"
+	reasonNotificationsSyncFailed = "NotificationsSyncFailed"
+	reasonTagsSyncFailed          = "TagsSyncFailed"
+	reasonTeamAccessSyncFailed    = "TeamAccessSyncFailed"
+	reasonRunStatusFailed         = "RunStatusFailed"
+	reasonRunSupersedeFailed      = "RunSupersedeFailed"
+	reasonConfigurationFailed     = "ConfigurationFailed"
+	reasonVariablesSyncFailed     = "VariablesSyncFailed"
+	reasonRunTriggersSyncFailed   = "RunTriggersSyncFailed"
+	reasonScheduleFailed          = "ScheduleFailed"
+	reasonRunStartFailed          = "RunStartFailed"
+	reasonRunStarted              = "RunStarted"
+	reasonRunInProgress           = "RunInProgress"
+	reasonRunNeedsApproval        = "RunNeedsApproval"
+	reasonPolicySoftFailed        = "PolicySoftFailed"
+	reasonRunCompleted            = "RunCompleted"
+	reasonRunErrored              = "RunErrored"
+	reasonRunCanceled             = "RunCanceled"
+	reasonRunDiscarded            = "RunDiscarded"
+	reasonOutputsSynced           = "OutputsSynced"
+	reasonOutputsSyncFailed       = "OutputsSyncFailed"
+	reasonChangesSynced           = "ChangesSynced"
+	reasonChangesSyncFailed       = "ChangesSyncFailed"
+	reasonReady                   = "Ready"
+	reasonNotDegraded             = "AsExpected"
+	reasonDriftCheckInProgress    = "DriftCheckInProgress"
+	reasonDriftCheckFailed        = "DriftCheckFailed"
+	reasonDriftDetected           = "DriftDetected"
+	reasonNoDrift                 = "NoDrift"
+)
+
+func setCondition(instance *appv1alpha1.Workspace, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: instance.Generation,
+	})
+}
+
+// phaseFailed records that a reconcile phase failed and returns the error unchanged
+func phaseFailed(instance *appv1alpha1.Workspace, reason string, err error) error {
+	setCondition(instance, appv1alpha1.ConditionReconciled, metav1.ConditionFalse, reason, err.Error())
+	return err
+}
+
+// phasesSucceeded records that Terraform Cloud matches the current generation of the spec
+func phasesSucceeded(instance *appv1alpha1.Workspace) {
+	setCondition(instance, appv1alpha1.ConditionReconciled, metav1.ConditionTrue, reasonReconciled,
+		fmt.Sprintf("Workspace %s matches generation %d of the spec", instance.Status.WorkspaceID, instance.Generation))
+	instance.Status.ObservedGeneration = instance.Generation
+}
+
+// setRunCondition reports the state of the current run
+func setRunCondition(instance *appv1alpha1.Workspace) {
+	runID := instance.Status.RunID
+	status := instance.Status.RunStatus
+	switch {
+	case awaitingApproval(instance):
+		setCondition(instance, appv1alpha1.ConditionRunSucceeded, metav1.ConditionUnknown, reasonRunNeedsApproval,
+			fmt.Sprintf("Run %s is %s and needs approval", runID, status))
+	case awaitingPolicyOverride(instance):
+		setCondition(instance, appv1alpha1.ConditionRunSucceeded, metav1.ConditionUnknown, reasonPolicySoftFailed,
+			fmt.Sprintf("Run %s failed soft-mandatory policies and needs an override", runID))
+	case isPending(status):
+		setCondition(instance, appv1alpha1.ConditionRunSucceeded, metav1.ConditionUnknown, reasonRunInProgress,
+			fmt.Sprintf("Run %s is %s", runID, status))
+	case isError(status):
+		setCondition(instance, appv1alpha1.ConditionRunSucceeded, metav1.ConditionFalse, reasonRunErrored,
+			fmt.Sprintf("Run %s errored", runID))
+	case tfc.RunStatus(status) == tfc.RunCanceled:
+		setCondition(instance, appv1alpha1.ConditionRunSucceeded, metav1.ConditionFalse, reasonRunCanceled,
+			fmt.Sprintf("Run %s was canceled", runID))
+	case tfc.RunStatus(status) == tfc.RunDiscarded:
+		setCondition(instance, appv1alpha1.ConditionRunSucceeded, metav1.ConditionFalse, reasonRunDiscarded,
+			fmt.Sprintf("Run %s was discarded", runID))
+	case runID != "":
+		setCondition(instance, appv1alpha1.ConditionRunSucceeded, metav1.ConditionTrue, reasonRunCompleted,
+			fmt.Sprintf("Run %s is %s", runID, status))
+	}
+}
+
+// setSummaryConditions derives Ready and Degraded from the conditions set by the reconcile phases
+func setSummaryConditions(instance *appv1alpha1.Workspace) {
+	conditions := instance.Status.Conditions
+	reconciled := meta.FindStatusCondition(conditions, appv1alpha1.ConditionReconciled)
+	run := meta.FindStatusCondition(conditions, appv1alpha1.ConditionRunSucceeded)
+	outputs := meta.FindStatusCondition(conditions, appv1alpha1.ConditionOutputsSynced)
+
+	switch {
+	case reconciled != nil && reconciled.Status == metav1.ConditionFalse:
+		setCondition(instance, appv1alpha1.ConditionDegraded, metav1.ConditionTrue, reconciled.Reason, reconciled.Message)
+	case run != nil && run.Reason == reasonRunErrored:
+		setCondition(instance, appv1alpha1.ConditionDegraded, metav1.ConditionTrue, run.Reason, run.Message)
+	case outputs != nil && outputs.Status == metav1.ConditionFalse:
+		setCondition(instance, appv1alpha1.ConditionDegraded, metav1.ConditionTrue, outputs.Reason, outputs.Message)
+	default:
+		setCondition(instance, appv1alpha1.ConditionDegraded, metav1.ConditionFalse, reasonNotDegraded, "")
+	}
+
+	for _, condition := range []*metav1.Condition{reconciled, run, outputs} {
+		if condition == nil {
+			continue
+		}
+		if condition.Status != metav1.ConditionTrue {
+			setCondition(instance, appv1alpha1.ConditionReady, metav1.ConditionFalse, condition.Reason, condition.Message)
+			return
+		}
+	}
+	if reconciled == nil || run == nil {
+		setCondition(instance, appv1alpha1.ConditionReady, metav1.ConditionUnknown, reasonRunInProgress,
+			"Waiting for the first reconcile and run to finish")
+		return
+	}
+	setCondition(instance, appv1alpha1.ConditionReady, metav1.ConditionTrue, reasonReady,
+		fmt.Sprintf("Run %s completed and the workspace matches the spec", instance.Status.RunID))
+}
+
+func conditionsEqual(a, b []metav1.Condition) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		c := meta.FindStatusCondition(b, a[i].Type)
+		if c == nil || c.Status != a[i].Status || c.Reason != a[i].Reason ||
+			c.Message != a[i].Message || c.ObservedGeneration != a[i].ObservedGeneration {
+			return false
+		}
+	}
+	return true
+}
+
+// updateConditions persists the conditions set during a reconcile when they differ from the previous status
+func (r *WorkspaceHelper) updateConditions(instance *appv1alpha1.Workspace, previous *appv1alpha1.WorkspaceStatus) error {
+	// The Workspace is gone once its finalizer is removed
+	if instance.GetDeletionTimestamp() != nil && !contains(instance.GetFinalizers(), workspaceFinalizer) {
+		return nil
+	}
+	setSummaryConditions(instance)
+	if conditionsEqual(previous.Conditions, instance.Status.Conditions) &&
+		previous.ObservedGeneration == instance.Status.ObservedGeneration {
+		return nil
+	}
+	if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+		r.reqLogger.Error(err, "Failed to update Workspace conditions")
+		return err
+	}
+	return nil
+}
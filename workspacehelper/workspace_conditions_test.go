@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRunConditionFollowsRunStatus(t *testing.T) {
+	for status, expected := range map[string]metav1.ConditionStatus{
+		"planning":  metav1.ConditionUnknown,
+		"applying":  metav1.ConditionUnknown,
+		"applied":   metav1.ConditionTrue,
+		"errored":   metav1.ConditionFalse,
+		"canceled":  metav1.ConditionFalse,
+		"discarded": metav1.ConditionFalse,
+	} {
+		workspace := &v1alpha1.Workspace{
+			Status: v1alpha1.WorkspaceStatus{RunID: "run-123", RunStatus: status},
+		}
+		setRunCondition(workspace)
+		condition := meta.FindStatusCondition(workspace.Status.Conditions, v1alpha1.ConditionRunSucceeded)
+		assert.Equal(t, expected, condition.Status, status)
+	}
+}
+
+func TestRunConditionReportsPendingApproval(t *testing.T) {
+	workspace := &v1alpha1.Workspace{
+		Spec:   v1alpha1.WorkspaceSpec{ApplyMode: v1alpha1.ApplyModeManual},
+		Status: v1alpha1.WorkspaceStatus{RunID: "run-123", RunStatus: "planned"},
+	}
+	setRunCondition(workspace)
+	condition := meta.FindStatusCondition(workspace.Status.Conditions, v1alpha1.ConditionRunSucceeded)
+	assert.Equal(t, metav1.ConditionUnknown, condition.Status)
+	assert.Equal(t, reasonRunNeedsApproval, condition.Reason)
+}
+
+func TestShouldBeReadyWhenAllPhasesSucceed(t *testing.T) {
+	workspace := &v1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{Generation: 3},
+		Status:     v1alpha1.WorkspaceStatus{RunID: "run-123", RunStatus: "applied"},
+	}
+	phasesSucceeded(workspace)
+	setRunCondition(workspace)
+	setCondition(workspace, v1alpha1.ConditionOutputsSynced, metav1.ConditionTrue, reasonOutputsSynced, "")
+	setSummaryConditions(workspace)
+
+	assert.True(t, meta.IsStatusConditionTrue(workspace.Status.Conditions, v1alpha1.ConditionReady))
+	assert.True(t, meta.IsStatusConditionFalse(workspace.Status.Conditions, v1alpha1.ConditionDegraded))
+	assert.Equal(t, int64(3), workspace.Status.ObservedGeneration)
+}
+
+func TestShouldBeDegradedWhenPhaseFails(t *testing.T) {
+	workspace := &v1alpha1.Workspace{
+		Status: v1alpha1.WorkspaceStatus{RunID: "run-123", RunStatus: "applied"},
+	}
+	setRunCondition(workspace)
+	err := phaseFailed(workspace, reasonVariablesSyncFailed, errors.New("could not update variable"))
+	assert.Error(t, err)
+	setSummaryConditions(workspace)
+
+	degraded := meta.FindStatusCondition(workspace.Status.Conditions, v1alpha1.ConditionDegraded)
+	assert.Equal(t, metav1.ConditionTrue, degraded.Status)
+	assert.Equal(t, reasonVariablesSyncFailed, degraded.Reason)
+	ready := meta.FindStatusCondition(workspace.Status.Conditions, v1alpha1.ConditionReady)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, reasonVariablesSyncFailed, ready.Reason)
+	assert.Equal(t, int64(0), workspace.Status.ObservedGeneration)
+}
+
+func TestShouldNotBeReadyWhileRunIsInProgress(t *testing.T) {
+	workspace := &v1alpha1.Workspace{
+		Status: v1alpha1.WorkspaceStatus{RunID: "run-123", RunStatus: "planning"},
+	}
+	phasesSucceeded(workspace)
+	setRunCondition(workspace)
+	setSummaryConditions(workspace)
+
+	assert.False(t, meta.IsStatusConditionTrue(workspace.Status.Conditions, v1alpha1.ConditionReady))
+	assert.True(t, meta.IsStatusConditionFalse(workspace.Status.Conditions, v1alpha1.ConditionDegraded))
+}
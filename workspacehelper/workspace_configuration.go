@@ -0,0 +1,157 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// mainFile is the file the configuration generated for the module is uploaded as
+const mainFile = "main.tf"
+
+// generatesConfiguration reports whether the operator uploads the configuration of the workspace
+func generatesConfiguration(instance *appv1alpha1.Workspace) bool {
+	return generatesTemplate(instance) || instance.Spec.Configuration != nil
+}
+
+// configurationFileName returns the file a key of a configuration ConfigMap is uploaded as
+func configurationFileName(key string) string {
+	if strings.HasSuffix(key, ".tf") {
+		return key
+	}
+	return key + ".tf"
+}
+
+// addConfigurationFile adds a file to the configuration unless another source already provides it
+func addConfigurationFile(files map[string]string, name, content, source string) error {
+	if _, ok := files[name]; ok {
+		return fmt.Errorf("configuration file %s of %s is defined more than once", name, source)
+	}
+	files[name] = content
+	return nil
+}
+
+// validateConfiguration checks that every file of the configuration is valid HCL
+func validateConfiguration(files map[string]string) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parser := hclparse.NewParser()
+	for _, name := range names {
+		if _, diags := parser.ParseHCL([]byte(files[name]), name); diags.HasErrors() {
+			return fmt.Errorf("invalid Terraform configuration: %s", diags.Error())
+		}
+	}
+	return nil
+}
+
+// configMapData stores the files of the configuration in the Terraform ConfigMap, the generated
+// main.tf under the key it has always used
+func configMapData(files map[string]string) map[string]string {
+	data := make(map[string]string, len(files))
+	for name, content := range files {
+		if name == mainFile {
+			name = TerraformConfigMap
+		}
+		data[name] = content
+	}
+	return data
+}
+
+// configurationFiles reads the files of the configuration back from the Terraform ConfigMap
+func configurationFiles(data map[string]string) map[string]string {
+	files := make(map[string]string, len(data))
+	for key, content := range data {
+		if key == TerraformConfigMap {
+			key = mainFile
+		}
+		files[key] = content
+	}
+	return files
+}
+
+// getConfiguration assembles the files of the configuration: main.tf generated for the modules,
+// the inline files and the keys of the referenced ConfigMaps
+func (r *WorkspaceHelper) getConfiguration(instance *appv1alpha1.Workspace) (map[string]string, error) {
+	files := map[string]string{}
+	if generatesTemplate(instance) {
+		if err := validateModules(instance); err != nil {
+			return nil, err
+		}
+		if err := validateProviders(instance); err != nil {
+			return nil, err
+		}
+		name := instance.Status.WorkspaceName
+		if !isAdopted(instance) {
+			desired, err := workspaceName(instance, r.options)
+			if err != nil {
+				return nil, err
+			}
+			name = desired
+		}
+		terraform, err := CreateTerraformTemplate(instance, name, terraformHostname(os.Getenv("TF_URL")))
+		if err != nil {
+			return nil, err
+		}
+		files[mainFile] = string(terraform)
+	}
+
+	configuration := instance.Spec.Configuration
+	if configuration == nil {
+		return files, nil
+	}
+	for name, content := range configuration.Files {
+		if !strings.HasSuffix(name, ".tf") {
+			return nil, fmt.Errorf("configuration file %s does not end with .tf", name)
+		}
+		if err := addConfigurationFile(files, name, content, "spec.configuration.files"); err != nil {
+			return nil, err
+		}
+	}
+	for _, ref := range configuration.ConfigMapRefs {
+		configMap := &corev1.ConfigMap{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: ref.Name, Namespace: instance.Namespace}, configMap)
+		if err != nil {
+			return nil, fmt.Errorf("could not get configuration ConfigMap %s: %v", ref.Name, err)
+		}
+		for key, content := range configMap.Data {
+			source := fmt.Sprintf("ConfigMap %s", ref.Name)
+			if err := addConfigurationFile(files, configurationFileName(key), content, source); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+// uploadConfiguration stages the files of the configuration in a directory of its own, so concurrent
+// reconciles never upload each other's files, and uploads them to a configuration version
+func (t *TerraformCloudClient) uploadConfiguration(uploadURL string, files map[string]string) error {
+	directory, err := ioutil.TempDir("", "terraform-k8s-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(directory)
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(directory, name), []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	return t.UploadConfigurationFile(uploadURL, directory)
+}
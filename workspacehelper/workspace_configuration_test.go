@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	tfc "github.com/hashicorp/go-tfe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigurationFileName(t *testing.T) {
+	assert.Equal(t, "network.tf", configurationFileName("network"))
+	assert.Equal(t, "network.tf", configurationFileName("network.tf"))
+}
+
+func TestAddConfigurationFile(t *testing.T) {
+	files := map[string]string{mainFile: "module \"operator\" {}"}
+	assert.NoError(t, addConfigurationFile(files, "locals.tf", "locals {}", "ConfigMap extra"))
+	assert.Error(t, addConfigurationFile(files, mainFile, "locals {}", "ConfigMap extra"))
+	assert.Equal(t, "module \"operator\" {}", files[mainFile])
+}
+
+func TestValidateConfiguration(t *testing.T) {
+	assert.NoError(t, validateConfiguration(map[string]string{
+		"main.tf":   "resource \"random_pet\" \"name\" {\n  length = var.length\n}\n",
+		"locals.tf": "locals {\n  prefix = \"k8s\"\n}\n",
+	}))
+
+	err := validateConfiguration(map[string]string{"broken.tf": "resource \"random_pet\" {\n"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "broken.tf")
+}
+
+func TestConfigMapData(t *testing.T) {
+	files := map[string]string{mainFile: "terraform {}", "locals.tf": "locals {}"}
+	data := configMapData(files)
+	assert.Equal(t, map[string]string{TerraformConfigMap: "terraform {}", "locals.tf": "locals {}"}, data,
+		"main.tf keeps the key of existing ConfigMaps")
+	assert.Equal(t, files, configurationFiles(data))
+}
+
+func TestUploadConfigurationConcurrently(t *testing.T) {
+	var mu sync.Mutex
+	uploaded := map[string][]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.Method != http.MethodPut {
+			return
+		}
+		archive, err := gzip.NewReader(r.Body)
+		assert.NoError(t, err)
+		var names []string
+		files := tar.NewReader(archive)
+		for {
+			header, err := files.Next()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			names = append(names, header.Name)
+		}
+		sort.Strings(names)
+		mu.Lock()
+		uploaded[r.URL.Path] = names
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	client, err := tfc.NewClient(&tfc.Config{Address: srv.URL, Token: "token1", HTTPClient: srv.Client()})
+	assert.NoError(t, err)
+	cloud := &TerraformCloudClient{Client: client}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			files := map[string]string{mainFile: "terraform {}", fmt.Sprintf("workspace%d.tf", i): "locals {}"}
+			assert.NoError(t, cloud.uploadConfiguration(fmt.Sprintf("%s/upload/%d", srv.URL, i), files))
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, []string{mainFile, fmt.Sprintf("workspace%d.tf", i)}, uploaded[fmt.Sprintf("/upload/%d", i)],
+			"every upload only contains the files of its own configuration")
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	tfc "github.com/hashicorp/go-tfe"
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// costEstimateUnavailable is the cost estimate status of runs without a cost estimate
+const costEstimateUnavailable = "unavailable"
+
+func costEstimateDone(status string) bool {
+	switch tfc.CostEstimateStatus(status) {
+	case tfc.CostEstimatePending, tfc.CostEstimateQueued:
+		return false
+	default:
+		return true
+	}
+}
+
+// costEstimateOutdated reports whether the cost estimate in the status needs to be read again for the current run
+func costEstimateOutdated(instance *appv1alpha1.Workspace) bool {
+	status := instance.Status.RunStatus
+	if instance.Status.RunID == "" || (isPending(status) && !isConfirmable(status)) {
+		return false
+	}
+	estimate := instance.Status.CostEstimate
+	return estimate == nil || estimate.RunID != instance.Status.RunID || !costEstimateDone(estimate.Status)
+}
+
+// costKnown reports whether a cost estimate finished with the cost of the run
+func costKnown(estimate *appv1alpha1.CostEstimateStatus) bool {
+	return tfc.CostEstimateStatus(estimate.Status) == tfc.CostEstimateFinished
+}
+
+// overBudget reports whether a cost estimate exceeds the budget of the cost policy. Runs without a
+// finished estimate are over budget since their cost is unknown, unless the policy allows unknown costs.
+func overBudget(policy *appv1alpha1.CostPolicy, estimate *appv1alpha1.CostEstimateStatus) (bool, error) {
+	if !costKnown(estimate) {
+		return !policy.AllowUnknownCost, nil
+	}
+	max, err := strconv.ParseFloat(policy.MaxMonthlyDelta, 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid maxMonthlyDelta %q, %v", policy.MaxMonthlyDelta, err)
+	}
+	delta, err := strconv.ParseFloat(estimate.DeltaMonthlyCost, 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid monthly cost delta %q, %v", estimate.DeltaMonthlyCost, err)
+	}
+	return delta > max, nil
+}
+
+// currentRunOverBudget reports whether the current run exceeds the budget of the cost policy
+func currentRunOverBudget(instance *appv1alpha1.Workspace) bool {
+	estimate := instance.Status.CostEstimate
+	return instance.Spec.CostPolicy != nil && estimate != nil &&
+		estimate.RunID == instance.Status.RunID && estimate.OverBudget
+}
+
+// costHeld reports whether the current run is over budget and held for approval
+func costHeld(instance *appv1alpha1.Workspace) bool {
+	return currentRunOverBudget(instance) && instance.Spec.CostPolicy.Action == appv1alpha1.CostPolicyActionHold
+}
+
+// costRejected reports whether the current run is over budget and must be discarded
+func costRejected(instance *appv1alpha1.Workspace) bool {
+	return currentRunOverBudget(instance) && instance.Spec.CostPolicy.Action != appv1alpha1.CostPolicyActionHold
+}
+
+// costEstimateStatus converts the cost estimate of a run, which is nil when cost estimation is disabled
+func costEstimateStatus(runID string, costEstimate *tfc.CostEstimate) *appv1alpha1.CostEstimateStatus {
+	estimate := &appv1alpha1.CostEstimateStatus{RunID: runID, Status: costEstimateUnavailable}
+	if costEstimate != nil {
+		estimate.Status = string(costEstimate.Status)
+		estimate.PriorMonthlyCost = costEstimate.PriorMonthlyCost
+		estimate.ProposedMonthlyCost = costEstimate.ProposedMonthlyCost
+		estimate.DeltaMonthlyCost = costEstimate.DeltaMonthlyCost
+	}
+	return estimate
+}
+
+// updateCostEstimate records the cost estimate of the current run once it is planned
+func (r *WorkspaceHelper) updateCostEstimate(instance *appv1alpha1.Workspace) error {
+	if !costEstimateOutdated(instance) {
+		return nil
+	}
+
+	runID := instance.Status.RunID
+	costEstimate, err := r.tfclient.GetCostEstimate(runID)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not get cost estimate of run", "RunID", runID)
+		return err
+	}
+	estimate := costEstimateStatus(runID, costEstimate)
+
+	policy := instance.Spec.CostPolicy
+	if policy != nil && costEstimateDone(estimate.Status) {
+		if estimate.OverBudget, err = overBudget(policy, estimate); err != nil {
+			r.reqLogger.Error(err, "Could not check cost estimate", "RunID", runID)
+			return err
+		}
+	}
+	instance.Status.CostEstimate = estimate
+	if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+		r.reqLogger.Error(err, "Failed to update cost estimate")
+		return err
+	}
+
+	if estimate.OverBudget && costKnown(estimate) {
+		r.recorder.Event(instance, corev1.EventTypeWarning, "WorkspaceEvent",
+			fmt.Sprintf("Run %s increases the monthly cost by %s, more than the maximum of %s",
+				runID, estimate.DeltaMonthlyCost, policy.MaxMonthlyDelta))
+	} else if estimate.OverBudget {
+		r.recorder.Event(instance, corev1.EventTypeWarning, "WorkspaceEvent",
+			fmt.Sprintf("Run %s has no cost estimate (%s), treating it as over budget unless costPolicy.allowUnknownCost is set",
+				runID, estimate.Status))
+	}
+	return nil
+}
+
+// enforceCostPolicy confirms runs within budget and discards runs over budget
+// once their cost estimate is known. Runs held for approval are left to the
+// approval flow. A cost_estimated run may still move on to its policy checks,
+// so the run is only confirmed or discarded once Terraform Cloud allows it.
+func (r *WorkspaceHelper) enforceCostPolicy(instance *appv1alpha1.Workspace) error {
+	runID := instance.Status.RunID
+	estimate := instance.Status.CostEstimate
+	if estimate == nil || estimate.RunID != runID || !costEstimateDone(estimate.Status) {
+		return nil
+	}
+
+	actions, err := r.tfclient.GetRunActions(runID)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not get actions of run", "RunID", runID)
+		return err
+	}
+
+	if estimate.OverBudget {
+		if !actions.IsDiscardable {
+			return nil
+		}
+		comment := fmt.Sprintf("%s, over the cost budget of %s", TerraformOperator, instance.Spec.CostPolicy.MaxMonthlyDelta)
+		if err := r.tfclient.DiscardRun(runID, comment); err != nil {
+			r.reqLogger.Error(err, "Could not discard run over budget", "RunID", runID)
+			return err
+		}
+		r.recorder.Event(instance, corev1.EventTypeWarning, "WorkspaceEvent",
+			fmt.Sprintf("Discarded run %s, it is over the cost budget", runID))
+		return nil
+	}
+
+	if !actions.IsConfirmable {
+		return nil
+	}
+	comment := fmt.Sprintf("%s, within the cost budget of %s", TerraformOperator, instance.Spec.CostPolicy.MaxMonthlyDelta)
+	if err := r.tfclient.ApplyRun(runID, comment); err != nil {
+		r.reqLogger.Error(err, "Could not apply run within budget", "RunID", runID)
+		return err
+	}
+	msg := fmt.Sprintf("Applying run %s, its monthly cost delta of %s is within budget", runID, estimate.DeltaMonthlyCost)
+	if !costKnown(estimate) {
+		msg = fmt.Sprintf("Applying run %s without a cost estimate (%s), as costPolicy.allowUnknownCost is set", runID, estimate.Status)
+	}
+	r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent", msg)
+	return nil
+}
@@ -0,0 +1,156 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestOverBudget(t *testing.T) {
+	policy := &v1alpha1.CostPolicy{MaxMonthlyDelta: "100.00"}
+	for delta, expected := range map[string]bool{
+		"-25.50": false,
+		"0.0":    false,
+		"100.00": false,
+		"100.01": true,
+		"1250.4": true,
+	} {
+		over, err := overBudget(policy, &v1alpha1.CostEstimateStatus{Status: "finished", DeltaMonthlyCost: delta})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, over, delta)
+	}
+}
+
+func TestOverBudgetWithoutFinishedEstimate(t *testing.T) {
+	policy := &v1alpha1.CostPolicy{MaxMonthlyDelta: "100"}
+	for _, status := range []string{"errored", "canceled", "skipped_due_to_targeting", costEstimateUnavailable} {
+		over, err := overBudget(policy, &v1alpha1.CostEstimateStatus{Status: status})
+		assert.NoError(t, err)
+		assert.True(t, over, status)
+	}
+}
+
+func TestOverBudgetWithUnknownCostAllowed(t *testing.T) {
+	policy := &v1alpha1.CostPolicy{MaxMonthlyDelta: "100", AllowUnknownCost: true}
+	over, err := overBudget(policy, &v1alpha1.CostEstimateStatus{Status: costEstimateUnavailable})
+	assert.NoError(t, err)
+	assert.False(t, over)
+
+	over, err = overBudget(policy, &v1alpha1.CostEstimateStatus{Status: "finished", DeltaMonthlyCost: "120"})
+	assert.NoError(t, err)
+	assert.True(t, over)
+}
+
+func TestEnforceCostPolicyWaitsUntilRunIsConfirmable(t *testing.T) {
+	runResponse := `{"data": {"id": "run-1", "type": "runs",
+		"attributes": {"status": "cost_estimated", "actions": {"is-confirmable": %t, "is-discardable": %t}}}}`
+	tests := []struct {
+		name       string
+		overBudget bool
+		actionable bool
+		wantPath   string
+	}{
+		{name: "Within budget before policy checks"},
+		{name: "Within budget", actionable: true, wantPath: "/api/v2/runs/run-1/actions/apply"},
+		{name: "Over budget before policy checks", overBudget: true},
+		{name: "Over budget", overBudget: true, actionable: true, wantPath: "/api/v2/runs/run-1/actions/discard"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posts []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/vnd.api+json")
+				if r.Method == http.MethodPost {
+					posts = append(posts, r.URL.Path)
+					w.WriteHeader(http.StatusAccepted)
+					return
+				}
+				fmt.Fprintf(w, runResponse, tt.actionable, tt.actionable)
+			}))
+			defer srv.Close()
+			client, err := tfe.NewClient(&tfe.Config{
+				Address:    srv.URL,
+				Token:      "token1",
+				HTTPClient: srv.Client(),
+			})
+			assert.NoError(t, err)
+
+			r := &WorkspaceHelper{
+				tfclient:  &TerraformCloudClient{Client: client},
+				reqLogger: log,
+				recorder:  record.NewFakeRecorder(10),
+			}
+			workspace := &v1alpha1.Workspace{
+				Spec: v1alpha1.WorkspaceSpec{CostPolicy: &v1alpha1.CostPolicy{MaxMonthlyDelta: "10"}},
+				Status: v1alpha1.WorkspaceStatus{
+					RunID:     "run-1",
+					RunStatus: "cost_estimated",
+					CostEstimate: &v1alpha1.CostEstimateStatus{RunID: "run-1", Status: "finished",
+						DeltaMonthlyCost: "5", OverBudget: tt.overBudget},
+				},
+			}
+			assert.NoError(t, r.enforceCostPolicy(workspace))
+			if tt.wantPath == "" {
+				assert.Empty(t, posts)
+				return
+			}
+			assert.Equal(t, []string{tt.wantPath}, posts)
+		})
+	}
+}
+
+func TestCostEstimateOutdated(t *testing.T) {
+	workspace := &v1alpha1.Workspace{
+		Status: v1alpha1.WorkspaceStatus{RunID: "run-2", RunStatus: "planning"},
+	}
+	assert.False(t, costEstimateOutdated(workspace))
+
+	workspace.Status.RunStatus = "cost_estimated"
+	assert.True(t, costEstimateOutdated(workspace))
+
+	workspace.Status.CostEstimate = &v1alpha1.CostEstimateStatus{RunID: "run-2", Status: "pending"}
+	assert.True(t, costEstimateOutdated(workspace))
+
+	workspace.Status.CostEstimate.Status = "finished"
+	assert.False(t, costEstimateOutdated(workspace))
+}
+
+func TestShouldAwaitApprovalForRunsHeldOverBudget(t *testing.T) {
+	workspace := &v1alpha1.Workspace{
+		Spec: v1alpha1.WorkspaceSpec{
+			CostPolicy: &v1alpha1.CostPolicy{MaxMonthlyDelta: "10", Action: v1alpha1.CostPolicyActionHold},
+		},
+		Status: v1alpha1.WorkspaceStatus{
+			RunID:        "run-1",
+			RunStatus:    "cost_estimated",
+			CostEstimate: &v1alpha1.CostEstimateStatus{RunID: "run-1", Status: "finished", OverBudget: true},
+		},
+	}
+	assert.True(t, awaitingApproval(workspace))
+
+	workspace.Spec.CostPolicy.Action = v1alpha1.CostPolicyActionDiscard
+	assert.False(t, awaitingApproval(workspace))
+
+	workspace.Spec.ApplyMode = v1alpha1.ApplyModeManual
+	assert.False(t, awaitingApproval(workspace), "runs over budget are discarded even in manual mode")
+
+	workspace.Status.CostEstimate.OverBudget = false
+	assert.True(t, awaitingApproval(workspace))
+}
+
+func TestWorkspaceAutoApplyIsDisabledByCostPolicy(t *testing.T) {
+	workspace := &v1alpha1.Workspace{}
+	assert.True(t, workspaceAutoApply(workspace))
+
+	workspace.Spec.CostPolicy = &v1alpha1.CostPolicy{MaxMonthlyDelta: "10"}
+	assert.False(t, workspaceAutoApply(workspace))
+}
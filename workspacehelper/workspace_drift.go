@@ -0,0 +1,219 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tfc "github.com/hashicorp/go-tfe"
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// driftCheckDue reports whether the interval since the last drift check elapsed
+func driftCheckDue(instance *appv1alpha1.Workspace, now time.Time) bool {
+	drift := instance.Status.Drift
+	if drift == nil || drift.LastCheckTime == nil {
+		return true
+	}
+	return !now.Before(drift.LastCheckTime.Add(instance.Spec.DriftDetection.Interval.Duration))
+}
+
+// isDriftRun reports whether a run is the latest refresh-only run queued for drift detection
+func isDriftRun(instance *appv1alpha1.Workspace, runID string) bool {
+	return instance.Status.Drift != nil && instance.Status.Drift.RunID == runID
+}
+
+// driftCheckPlanned reports whether a refresh-only run that is not speculative finished planning
+// and waits to be discarded. Failed soft-mandatory policies wait for an override instead of a
+// confirmation, but the plan can be read all the same.
+func driftCheckPlanned(status string) bool {
+	return isConfirmable(status) || tfc.RunStatus(status) == tfc.RunPolicySoftFailed
+}
+
+// driftCheckRunning reports whether the refresh-only run has not produced a plan yet
+func driftCheckRunning(drift *appv1alpha1.DriftStatus) bool {
+	return isPending(drift.RunStatus) && !driftCheckPlanned(drift.RunStatus)
+}
+
+func (r *WorkspaceHelper) updateDriftStatus(instance *appv1alpha1.Workspace) error {
+	if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+		r.reqLogger.Error(err, "Failed to update drift status")
+		return err
+	}
+	return nil
+}
+
+// refreshDriftCheck clears the drift status when drift detection is turned off, and reads the result of
+// the refresh-only run once it is planned. It runs before anything waits on the run queue of the
+// workspace, because the refresh-only run holds that queue until it is discarded.
+func (r *WorkspaceHelper) refreshDriftCheck(instance *appv1alpha1.Workspace) error {
+	if instance.Spec.DriftDetection == nil {
+		if instance.Status.Drift == nil {
+			return nil
+		}
+		instance.Status.Drift = nil
+		meta.RemoveStatusCondition(&instance.Status.Conditions, appv1alpha1.ConditionDrifted)
+		return r.updateDriftStatus(instance)
+	}
+	if instance.Status.Drift == nil {
+		instance.Status.Drift = &appv1alpha1.DriftStatus{}
+	}
+	drift := instance.Status.Drift
+	if drift.RunID == "" || !isPending(drift.RunStatus) {
+		return nil
+	}
+
+	runStatus, err := r.tfclient.CheckRun(drift.RunID)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not get drift detection run", "RunID", drift.RunID)
+		setCondition(instance, appv1alpha1.ConditionDrifted, metav1.ConditionUnknown, reasonDriftCheckFailed, err.Error())
+		return err
+	}
+	drift.RunStatus = runStatus
+	if driftCheckRunning(drift) {
+		return r.updateDriftStatus(instance)
+	}
+	return r.processDriftCheck(instance)
+}
+
+// remediationDue reports whether the latest drift check found drift that no run was started for yet
+func remediationDue(instance *appv1alpha1.Workspace) bool {
+	drift := instance.Status.Drift
+	return instance.Spec.DriftDetection.Policy == appv1alpha1.DriftPolicyRemediate &&
+		len(drift.DriftedResources) > 0 && drift.RemediationRunID == ""
+}
+
+// reconcileDrift remediates the drift found by the latest check, and queues refresh-only runs on the
+// drift detection interval. It returns true when a corrective run was started.
+func (r *WorkspaceHelper) reconcileDrift(instance *appv1alpha1.Workspace) (bool, error) {
+	if instance.Spec.DriftDetection == nil || isPending(instance.Status.Drift.RunStatus) {
+		return false, nil
+	}
+	drift := instance.Status.Drift
+
+	if remediationDue(instance) {
+		started, err := r.startRun(instance, appv1alpha1.RunSourceDriftRemediation)
+		if err != nil || !started {
+			return false, err
+		}
+		drift.RemediationRunID = instance.Status.RunID
+		if err := r.updateDriftStatus(instance); err != nil {
+			return false, err
+		}
+		r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent",
+			fmt.Sprintf("Started run %s to remediate drift", instance.Status.RunID))
+		return true, nil
+	}
+
+	if !driftCheckDue(instance, time.Now()) {
+		return false, nil
+	}
+
+	// Plan on an uploaded speculative configuration when the operator generates the configuration,
+	// so the refresh-only plan does not lock the workspace
+	var configVersion *tfc.ConfigurationVersion
+	if plannable(instance) {
+		var err error
+		configVersion, err = r.driftConfiguration(instance)
+		if err != nil {
+			r.reqLogger.Error(err, "Could not upload drift detection configuration", "WorkspaceID", instance.Status.WorkspaceID)
+			setCondition(instance, appv1alpha1.ConditionDrifted, metav1.ConditionUnknown, reasonDriftCheckFailed, err.Error())
+			return false, err
+		} else if configVersion == nil {
+			// The check is queued on a later reconcile, once the upload is processed
+			return false, nil
+		}
+	}
+
+	run, err := r.tfclient.CreateRefreshOnlyRun(instance.Status.WorkspaceID, configVersion)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not queue drift detection run", "WorkspaceID", instance.Status.WorkspaceID)
+		setCondition(instance, appv1alpha1.ConditionDrifted, metav1.ConditionUnknown, reasonDriftCheckFailed, err.Error())
+		return false, err
+	}
+	now := metav1.Now()
+	drift.ConfigVersionID = ""
+	drift.RunID = run.ID
+	drift.RunStatus = string(run.Status)
+	drift.LastCheckTime = &now
+	if meta.FindStatusCondition(instance.Status.Conditions, appv1alpha1.ConditionDrifted) == nil {
+		setCondition(instance, appv1alpha1.ConditionDrifted, metav1.ConditionUnknown, reasonDriftCheckInProgress,
+			fmt.Sprintf("Checking for drift with run %s", run.ID))
+	}
+	r.reqLogger.Info("Queued drift detection run", "WorkspaceID", instance.Status.WorkspaceID, "RunID", run.ID)
+	return false, r.updateDriftStatus(instance)
+}
+
+// driftConfiguration uploads the configuration of the workspace to a speculative configuration
+// version for the next refresh-only run. It returns nil until the upload is processed.
+func (r *WorkspaceHelper) driftConfiguration(instance *appv1alpha1.Workspace) (*tfc.ConfigurationVersion, error) {
+	drift := instance.Status.Drift
+	if drift.ConfigVersionID == "" {
+		cfgMap := &corev1.ConfigMap{}
+		key := types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}
+		if err := r.client.Get(context.TODO(), key, cfgMap); err != nil {
+			return nil, err
+		}
+		configVersion, err := r.tfclient.CreateSpeculativeConfigurationVersion(instance.Status.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.tfclient.uploadConfiguration(configVersion.UploadURL, configurationFiles(cfgMap.Data)); err != nil {
+			return nil, err
+		}
+		drift.ConfigVersionID = configVersion.ID
+		if err := r.updateDriftStatus(instance); err != nil {
+			return nil, err
+		}
+	}
+	return r.tfclient.UploadedConfigurationVersion(drift.ConfigVersionID)
+}
+
+// processDriftCheck reads the drifted resources from a finished refresh-only
+// run and discards it so the state is left untouched.
+func (r *WorkspaceHelper) processDriftCheck(instance *appv1alpha1.Workspace) error {
+	drift := instance.Status.Drift
+	if isError(drift.RunStatus) || tfc.RunStatus(drift.RunStatus) == tfc.RunCanceled ||
+		tfc.RunStatus(drift.RunStatus) == tfc.RunDiscarded {
+		msg := fmt.Sprintf("Drift detection run %s is %s", drift.RunID, drift.RunStatus)
+		setCondition(instance, appv1alpha1.ConditionDrifted, metav1.ConditionUnknown, reasonDriftCheckFailed, msg)
+		r.recorder.Event(instance, corev1.EventTypeWarning, "WorkspaceEvent", msg)
+		return r.updateDriftStatus(instance)
+	}
+
+	drifted, err := r.tfclient.CheckDrift(drift.RunID)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not read drift detection plan", "RunID", drift.RunID)
+		setCondition(instance, appv1alpha1.ConditionDrifted, metav1.ConditionUnknown, reasonDriftCheckFailed, err.Error())
+		return err
+	}
+	if driftCheckPlanned(drift.RunStatus) {
+		comment := fmt.Sprintf("%s, drift detection only", TerraformOperator)
+		if err := r.tfclient.DiscardRun(drift.RunID, comment); err != nil {
+			r.reqLogger.Error(err, "Could not discard drift detection run", "RunID", drift.RunID)
+			return err
+		}
+		drift.RunStatus = string(tfc.RunDiscarded)
+	}
+	drift.DriftedResources = drifted
+	drift.RemediationRunID = ""
+
+	if len(drifted) == 0 {
+		setCondition(instance, appv1alpha1.ConditionDrifted, metav1.ConditionFalse, reasonNoDrift,
+			fmt.Sprintf("Run %s found no drift", drift.RunID))
+		return r.updateDriftStatus(instance)
+	}
+
+	msg := fmt.Sprintf("Run %s found %d drifted resources: %s", drift.RunID, len(drifted), strings.Join(drifted, ", "))
+	setCondition(instance, appv1alpha1.ConditionDrifted, metav1.ConditionTrue, reasonDriftDetected, msg)
+	r.recorder.Event(instance, corev1.EventTypeWarning, "WorkspaceEvent", msg)
+	return r.updateDriftStatus(instance)
+}
@@ -6,9 +6,9 @@ package workspacehelper
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"reflect"
+	"text/template"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -17,6 +17,7 @@ import (
 	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -31,6 +32,20 @@ var log = logf.Log.WithName(TerraformOperator)
 const workspaceFinalizer = "finalizer.workspace.app.terraform.io"
 const requeueInterval = time.Minute * 1
 
+// Options configures the reconcilers for the whole operator
+type Options struct {
+	// ConfigMap whose suspend key suspends every Workspace, no maintenance switch when unset
+	MaintenanceConfigMap types.NamespacedName
+	// Reader of the maintenance ConfigMap, see NewMaintenanceReader
+	MaintenanceReader client.Reader
+	// Template of the names of Terraform Cloud workspaces, DefaultWorkspaceNameTemplate when unset
+	WorkspaceNameTemplate *template.Template
+	// Name of the cluster used by the workspace name template
+	ClusterName string
+	// Number of Workspaces and Runs reconciled at once, one when unset
+	MaxConcurrentReconciles int
+}
+
 type WorkspaceHelper struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
@@ -39,9 +54,10 @@ type WorkspaceHelper struct {
 	tfclient  *TerraformCloudClient
 	reqLogger logr.Logger
 	recorder  record.EventRecorder
+	options   Options
 }
 
-func NewWorkspaceHelper(mgr manager.Manager) reconcile.Reconciler {
+func NewWorkspaceHelper(mgr manager.Manager, options Options) reconcile.Reconciler {
 	tfclient := &TerraformCloudClient{}
 	err := tfclient.GetClient(os.Getenv("TF_URL"))
 	if err != nil {
@@ -54,25 +70,64 @@ func NewWorkspaceHelper(mgr manager.Manager) reconcile.Reconciler {
 		tfclient:  tfclient,
 		reqLogger: log,
 		recorder:  mgr.GetEventRecorderFor("workspace"),
+		options:   options,
+	}
+}
+
+// deletionPolicy returns the deletion policy of the Workspace, defaulting to Destroy
+// for workspaces created by the operator and to Orphan for adopted ones.
+func deletionPolicy(workspace *appv1alpha1.Workspace) appv1alpha1.DeletionPolicy {
+	if workspace.Spec.DeletionPolicy == "" && isAdopted(workspace) {
+		return appv1alpha1.DeletionPolicyOrphan
+	} else if workspace.Spec.DeletionPolicy == "" {
+		return appv1alpha1.DeletionPolicyDestroy
 	}
+	return workspace.Spec.DeletionPolicy
 }
 
 func (r *WorkspaceHelper) finalizeWorkspace(reqLogger logr.Logger, workspace *appv1alpha1.Workspace) error {
+	policy := deletionPolicy(workspace)
+	if policy == appv1alpha1.DeletionPolicyOrphan {
+		reqLogger.Info("Leaving workspace and resources in place", "Name", workspace.Name,
+			"Namespace", workspace.Namespace, "WorkspaceID", workspace.Status.WorkspaceID)
+		msg := fmt.Sprintf("Orphaned workspace %s and its resources", workspace.Status.WorkspaceID)
+		if isAdopted(workspace) {
+			msg = fmt.Sprintf("Released workspace %s back to unmanaged", workspace.Status.WorkspaceID)
+		}
+		r.recorder.Event(workspace, corev1.EventTypeNormal, "WorkspaceEvent", msg)
+		reqLogger.Info("Successfully finalized workspace")
+		return nil
+	}
+
 	if err := r.tfclient.CheckWorkspacebyID(workspace.Status.WorkspaceID); err == nil {
 		reqLogger.Info("Stopping runs in workspace",
 			"Name", workspace.Name, "Namespace", workspace.Namespace)
 		if err := r.tfclient.DeleteRuns(workspace.Status.WorkspaceID); err != nil {
 			return err
 		}
-		reqLogger.Info("Deleting resources in workspace", "Name", workspace.Name,
-			"Namespace", workspace.Namespace)
-		if err := r.tfclient.DeleteResources(workspace.Status.WorkspaceID); err != nil {
-			return err
+		if policy == appv1alpha1.DeletionPolicyDestroy {
+			reqLogger.Info("Deleting resources in workspace", "Name", workspace.Name,
+				"Namespace", workspace.Namespace)
+			r.recorder.Event(workspace, corev1.EventTypeNormal, "WorkspaceEvent",
+				fmt.Sprintf("Destroying resources in workspace %s", workspace.Status.WorkspaceID))
+			if err := r.tfclient.DeleteResources(workspace.Status.WorkspaceID); err != nil {
+				r.recorder.Event(workspace, corev1.EventTypeWarning, "WorkspaceEvent",
+					fmt.Sprintf("Could not destroy resources in workspace %s: %s", workspace.Status.WorkspaceID, err))
+				return err
+			}
+		} else {
+			r.recorder.Event(workspace, corev1.EventTypeNormal, "WorkspaceEvent",
+				fmt.Sprintf("Retaining resources of workspace %s", workspace.Status.WorkspaceID))
 		}
 		reqLogger.Info("Deleting workspace", "Name", workspace.Name,
 			"Namespace", workspace.Namespace)
 		if err := r.tfclient.DeleteWorkspace(workspace.Status.WorkspaceID); err != nil {
 			reqLogger.Error(err, "Could not delete workspace")
+			r.recorder.Event(workspace, corev1.EventTypeWarning, "WorkspaceEvent",
+				fmt.Sprintf("Could not delete workspace %s: %s", workspace.Status.WorkspaceID, err))
+		} else {
+			r.recorder.Event(workspace, corev1.EventTypeNormal, "WorkspaceEvent",
+				fmt.Sprintf("Deleted workspace %s", workspace.Status.WorkspaceID))
 		}
 	}
 	reqLogger.Info("Successfully finalized workspace")
@@ -92,6 +147,19 @@ func (r *WorkspaceHelper) addFinalizer(reqLogger logr.Logger, workspace *appv1al
 	return nil
 }
 
+// patchMetadata patches the changes mutate makes to the metadata of a copy of the Workspace.
+// Working on a copy keeps the status set earlier in the reconcile from being replaced by the
+// copy of the server, so it can still be written afterwards.
+func (r *WorkspaceHelper) patchMetadata(workspace *appv1alpha1.Workspace, mutate func(*appv1alpha1.Workspace)) error {
+	patched := workspace.DeepCopy()
+	mutate(patched)
+	if err := r.client.Patch(context.TODO(), patched, client.MergeFrom(workspace)); err != nil {
+		return err
+	}
+	workspace.ObjectMeta = patched.ObjectMeta
+	return nil
+}
+
 func (r *WorkspaceHelper) initializeReconciliation(request reconcile.Request) (*appv1alpha1.Workspace, error) {
 	// Fetch the Workspace instance
 	instance := &appv1alpha1.Workspace{}
@@ -107,30 +175,31 @@ func (r *WorkspaceHelper) initializeReconciliation(request reconcile.Request) (*
 		return nil, err
 	}
 
-	if instance.Spec.VCS == nil && instance.Spec.Module == nil {
-		msg := fmt.Sprintf("Either VCS or Module need to be specified in spec for workspace %s", instance.Name)
+	if instance.Spec.VCS == nil && !generatesConfiguration(instance) && !isAdopted(instance) {
+		msg := fmt.Sprintf("Either VCS, Module or Configuration need to be specified in spec for workspace %s", instance.Name)
 		r.recorder.Event(instance, corev1.EventTypeWarning, "WorkspaceEvent", msg)
 	}
 
-	r.tfclient.Organization = instance.Spec.Organization
-	if err := r.tfclient.CheckOrganization(); err != nil {
+	if err := r.tfclient.CheckOrganization(instance.Spec.Organization); err != nil {
 		r.reqLogger.Error(err, "Could not find organization", "Organization", instance.Spec.Organization)
-		return nil, err
+		return instance, err
 	}
 
-	r.tfclient.SecretsMountPath = instance.Spec.SecretsMountPath
-	if err := r.tfclient.CheckSecretsMountPath(); err != nil {
+	if err := CheckSecretsMountPath(instance.Spec.SecretsMountPath); err != nil {
 		r.reqLogger.Error(err, "Could not find secrets mount path")
-		return nil, err
+		return instance, err
 	}
 
 	return instance, nil
 }
 
 func (r *WorkspaceHelper) reconcileWorkspace(instance *appv1alpha1.Workspace) error {
-	workspace := fmt.Sprintf("%s-%s", instance.Namespace, instance.Name)
-	if instance.Spec.OmitNamespacePrefix {
-		workspace = fmt.Sprintf("%s", instance.Name)
+	workspace, err := workspaceName(instance, r.options)
+	if err != nil {
+		return err
+	}
+	if err := r.checkNameCollision(instance, workspace); err != nil {
+		return err
 	}
 
 	organization := instance.Spec.Organization
@@ -140,29 +209,80 @@ func (r *WorkspaceHelper) reconcileWorkspace(instance *appv1alpha1.Workspace) er
 		r.reqLogger.Error(err, "Could not update workspace")
 		return err
 	}
+	if err := r.reconcileWorkspaceName(instance, ws, workspace); err != nil {
+		return err
+	}
 	workspaceID := ws.ID
 
+	if instance.Status.WorkspaceName != ws.Name && instance.Status.WorkspaceID == workspaceID {
+		instance.Status.WorkspaceName = ws.Name
+		if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+			r.reqLogger.Error(err, "Failed to update workspace name")
+			return err
+		}
+	}
+
 	if instance.Status.WorkspaceID != workspaceID {
 		instance.Status.WorkspaceID = workspaceID
+		instance.Status.WorkspaceName = ws.Name
 		instance.Status.Outputs = []*appv1alpha1.OutputStatus{}
+		if isAdopted(instance) && ws.CurrentRun != nil {
+			// Bind to the latest run of the adopted workspace instead of queuing a new one
+			run, err := r.tfclient.Client.Runs.Read(context.TODO(), ws.CurrentRun.ID)
+			if err != nil {
+				r.reqLogger.Error(err, "Could not get run of adopted workspace")
+				return err
+			}
+			instance.Status.RunID = run.ID
+			instance.Status.RunStatus = string(run.Status)
+			r.recordRunFingerprint(instance)
+			r.recordRun(instance, run, runSource(run))
+		}
 		if err := r.client.Status().Update(context.TODO(), instance); err != nil {
 			r.reqLogger.Error(err, "Failed to update output status")
 			return err
 		}
 		r.reqLogger.Info("Updated workspace ID", "Organization", organization,
 			"WorkspaceID", instance.Status.WorkspaceID)
+		if isAdopted(instance) {
+			r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent",
+				fmt.Sprintf("Adopted existing workspace %s (%s)", ws.Name, ws.ID))
+		}
 	}
 
-	if instance.Status.RunID != "" && ws.CurrentRun != nil && instance.Status.RunID != ws.CurrentRun.ID {
-		instance.Status.RunID = ws.CurrentRun.ID
-		if err := r.client.Status().Update(context.TODO(), instance); err != nil {
-			r.reqLogger.Error(err, "Failed to update workspace status")
+	if instance.Status.RunID != "" && ws.CurrentRun != nil && instance.Status.RunID != ws.CurrentRun.ID &&
+		!isDriftRun(instance, ws.CurrentRun.ID) && !runObjectRunRecorded(instance, ws.CurrentRun.ID) {
+		run, err := r.tfclient.Client.Runs.Read(context.TODO(), ws.CurrentRun.ID)
+		if err != nil {
+			r.reqLogger.Error(err, "Could not get out of band run")
 			return err
 		}
-		r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent",
-			"Updated outputs after out of band run applied")
-		r.reqLogger.Info("Updated Run ID", "Organization", organization,
-			"WorkspaceID", instance.Status.WorkspaceID, "RunID", instance.Status.RunID)
+		found, creating, err := r.findRunObjectRun(instance, run.ID)
+		if err != nil {
+			r.reqLogger.Error(err, "Could not list Runs")
+			return err
+		}
+		if found {
+			if err := r.recordRunObjectRun(instance, run); err != nil {
+				return err
+			}
+		} else if creating {
+			// A Run has not recorded its run yet, the run is told apart on a later reconcile
+			r.reqLogger.Info("Waiting for Run to record its run", "RunID", run.ID)
+		} else {
+			instance.Status.RunID = run.ID
+			instance.Status.RunStatus = string(run.Status)
+			r.recordRunFingerprint(instance)
+			r.recordRun(instance, run, runSource(run))
+			if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+				r.reqLogger.Error(err, "Failed to update workspace status")
+				return err
+			}
+			r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent",
+				"Updated outputs after out of band run applied")
+			r.reqLogger.Info("Updated Run ID", "Organization", organization,
+				"WorkspaceID", instance.Status.WorkspaceID, "RunID", instance.Status.RunID)
+		}
 	}
 
 	// Add finalizer for this CR
@@ -186,21 +306,24 @@ func (r *WorkspaceHelper) condDeleteWorkspace(instance *appv1alpha1.Workspace) (
 		return false, err
 	}
 
+	if err := r.finalizeAndRemoveFinalizer(instance); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// finalizeAndRemoveFinalizer applies the deletion policy of the Workspace and removes its finalizer
+func (r *WorkspaceHelper) finalizeAndRemoveFinalizer(instance *appv1alpha1.Workspace) error {
 	if contains(instance.GetFinalizers(), workspaceFinalizer) {
 		if err := r.finalizeWorkspace(r.reqLogger, instance); err != nil {
-			return false, err
+			return err
 		}
 	}
 
 	// Remove workspaceFinalizer. Once all finalizers have been
 	// removed, the object will be deleted.
 	instance.SetFinalizers(remove(instance.GetFinalizers(), workspaceFinalizer))
-	err = r.client.Update(context.TODO(), instance)
-	if err != nil {
-		return false, err
-	}
-
-	return true, nil
+	return r.client.Update(context.TODO(), instance)
 }
 
 func (r *WorkspaceHelper) runInProgress(instance *appv1alpha1.Workspace) (bool, error) {
@@ -210,26 +333,68 @@ func (r *WorkspaceHelper) runInProgress(instance *appv1alpha1.Workspace) (bool,
 
 	r.reqLogger.Info("Run incomplete", "Organization", instance.Spec.Organization,
 		"RunID", instance.Status.RunID, "RunStatus", instance.Status.RunStatus)
+	if err := r.refreshRun(instance); err != nil {
+		return false, err
+	}
+
+	if awaitingPolicyOverride(instance) {
+		return true, r.reconcilePolicyOverride(instance)
+	} else if awaitingApproval(instance) {
+		return true, r.reconcileApproval(instance)
+	} else if instance.Spec.CostPolicy != nil && isConfirmable(instance.Status.RunStatus) {
+		return true, r.enforceCostPolicy(instance)
+	}
+	return true, nil
+}
+
+// refreshRun updates the status of the current run and the results of its plan, cost estimate and policy checks
+func (r *WorkspaceHelper) refreshRun(instance *appv1alpha1.Workspace) error {
 	runStatus, err := r.tfclient.CheckRun(instance.Status.RunID)
 	if err != nil {
 		r.reqLogger.Error(err, "Could not get run ID")
-		return false, err
+		return err
 	}
 
 	if instance.Status.RunStatus != runStatus {
 		instance.Status.RunStatus = runStatus
+		setRunHistoryStatus(instance, instance.Status.RunID, runStatus, time.Now())
 		if err := r.client.Status().Update(context.TODO(), instance); err != nil {
 			r.reqLogger.Error(err, "Failed to update Workspace status")
-			return false, err
+			return err
 		}
 	}
-	return true, nil
+
+	if err := r.updateRunChanges(instance); err != nil {
+		return err
+	}
+	if err := r.updateCostEstimate(instance); err != nil {
+		return err
+	}
+	return r.updatePolicyCheck(instance)
 }
 
 func (r *WorkspaceHelper) processFinishedRun(instance *appv1alpha1.Workspace) error {
+	if err := r.updateRunChanges(instance); err != nil {
+		return err
+	}
+
+	// Logs are only kept for troubleshooting, not being able to read them
+	// should not block the reconcile.
+	summary, err := r.captureRunLogs(instance)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not capture run logs", "RunID", instance.Status.RunID)
+		r.recorder.Event(instance, corev1.EventTypeWarning, "WorkspaceEvent",
+			fmt.Sprintf("Could not capture logs of run %s: %s", instance.Status.RunID, err))
+	}
+
 	if isError(instance.Status.RunStatus) {
 		msg := fmt.Sprintf("Run %q for workspace %q failed to complete",
 			instance.Status.RunID, instance.Status.WorkspaceID)
+		if summary != "" {
+			msg = fmt.Sprintf("%s: %s", msg, summary)
+			setCondition(instance, appv1alpha1.ConditionRunSucceeded, metav1.ConditionFalse, reasonRunErrored,
+				fmt.Sprintf("Run %s errored: %s", instance.Status.RunID, summary))
+		}
 		r.recorder.Event(instance, corev1.EventTypeNormal,
 			"WorkspaceEvent", msg)
 
@@ -244,6 +409,7 @@ func (r *WorkspaceHelper) processFinishedRun(instance *appv1alpha1.Workspace) er
 	outputs, err := r.tfclient.CheckOutputs(instance.Status.WorkspaceID, instance.Status.RunID)
 	if err != nil {
 		r.reqLogger.Error(err, "Could not get run ID")
+		setCondition(instance, appv1alpha1.ConditionOutputsSynced, metav1.ConditionFalse, reasonOutputsSyncFailed, err.Error())
 		return err
 	}
 
@@ -261,23 +427,32 @@ func (r *WorkspaceHelper) processFinishedRun(instance *appv1alpha1.Workspace) er
 	}
 	if err = r.UpsertSecretOutputs(instance, instance.Status.Outputs); err != nil {
 		r.reqLogger.Error(err, "Error with creating ConfigMap for Terraform Outputs")
+		setCondition(instance, appv1alpha1.ConditionOutputsSynced, metav1.ConditionFalse, reasonOutputsSyncFailed, err.Error())
 		return err
 	}
+	setCondition(instance, appv1alpha1.ConditionOutputsSynced, metav1.ConditionTrue, reasonOutputsSynced,
+		fmt.Sprintf("Stored %d outputs of run %s in Secret %s-outputs", len(instance.Status.Outputs), instance.Status.RunID, instance.Name))
 	return nil
 }
 
 func (r *WorkspaceHelper) updateTerraformTemplate(instance *appv1alpha1.Workspace) (bool, error) {
 	if instance.Spec.VCS != nil {
+		return false, r.deleteTerraformConfig(instance)
+	} else if !generatesConfiguration(instance) {
 		return false, nil
 	}
 
-	terraform, err := CreateTerraformTemplate(instance)
+	files, err := r.getConfiguration(instance)
 	if err != nil {
 		r.reqLogger.Error(err, "Could not create Terraform configuration")
 		return false, err
 	}
+	if err := validateConfiguration(files); err != nil {
+		r.reqLogger.Error(err, "Terraform configuration does not parse")
+		return false, err
+	}
 
-	updated, err := r.UpsertTerraformConfig(instance, terraform)
+	updated, err := r.UpsertTerraformConfig(instance, configMapData(files))
 	if err != nil {
 		r.reqLogger.Error(err, "Error with creating ConfigMap for Terraform Configuration")
 		return false, err
@@ -286,11 +461,6 @@ func (r *WorkspaceHelper) updateTerraformTemplate(instance *appv1alpha1.Workspac
 }
 
 func (r *WorkspaceHelper) updateVariables(instance *appv1alpha1.Workspace) (bool, error) {
-	workspace := fmt.Sprintf("%s-%s", instance.Namespace, instance.Name)
-	if instance.Spec.OmitNamespacePrefix {
-		workspace = fmt.Sprintf("%s", instance.Name)
-	}
-
 	for _, variable := range instance.Spec.Variables {
 		err := r.GetConfigMapForVariable(instance.Namespace, variable)
 		if err != nil {
@@ -303,7 +473,8 @@ func (r *WorkspaceHelper) updateVariables(instance *appv1alpha1.Workspace) (bool
 	}
 
 	specTFCVariables := MapToTFCVariable(instance.Spec.Variables)
-	updatedVariables, err := r.tfclient.CheckVariables(workspace, specTFCVariables)
+	updatedVariables, err := r.tfclient.CheckVariables(instance.Status.WorkspaceID, specTFCVariables, !isAdopted(instance),
+		instance.Spec.SecretsMountPath)
 	if err != nil {
 		r.reqLogger.Error(err, "Could not update variables")
 		return false, err
@@ -313,12 +484,7 @@ func (r *WorkspaceHelper) updateVariables(instance *appv1alpha1.Workspace) (bool
 }
 
 func (r *WorkspaceHelper) updateRunTriggers(instance *appv1alpha1.Workspace) (bool, error) {
-	workspace := fmt.Sprintf("%s-%s", instance.Namespace, instance.Name)
-	if instance.Spec.OmitNamespacePrefix {
-		workspace = fmt.Sprintf("%s", instance.Name)
-	}
-
-	updatedRunTriggers, err := r.tfclient.CheckRunTriggers(workspace, instance.Spec.RunTriggers)
+	updatedRunTriggers, err := r.tfclient.CheckRunTriggers(instance.Spec.Organization, instance.Status.WorkspaceID, instance.Spec.RunTriggers, !isAdopted(instance))
 	if err != nil {
 		r.reqLogger.Error(err, "Could not update run triggers")
 		return false, err
@@ -327,7 +493,7 @@ func (r *WorkspaceHelper) updateRunTriggers(instance *appv1alpha1.Workspace) (bo
 	return updatedRunTriggers, nil
 }
 
-func (r *WorkspaceHelper) prepareModuleRun(instance *appv1alpha1.Workspace, options tfe.RunCreateOptions) (bool, error) {
+func (r *WorkspaceHelper) prepareModuleRun(instance *appv1alpha1.Workspace, options *tfe.RunCreateOptions) (bool, error) {
 	r.reqLogger.Info("Starting module backed run", "Organization",
 		instance.Spec.Organization, "Name", instance.Name, "Namespace", instance.Namespace)
 
@@ -343,23 +509,13 @@ func (r *WorkspaceHelper) prepareModuleRun(instance *appv1alpha1.Workspace, opti
 			return true, err
 		}
 
-		tf := []byte(cfgMap.Data[TerraformConfigMap])
+		files := configurationFiles(cfgMap.Data)
 		configVersion, err = r.tfclient.CreateConfigurationVersion(instance.Status.WorkspaceID)
 		if err != nil {
 			return true, err
 		}
 
-		if _, err := os.Stat(moduleDirectory); os.IsNotExist(err) {
-			if err = os.Mkdir(moduleDirectory, 0777); err != nil {
-				return true, err
-			}
-		}
-
-		if err = ioutil.WriteFile(configurationFilePath, tf, 0777); err != nil {
-			return true, err
-		}
-
-		if err = r.tfclient.UploadConfigurationFile(configVersion.UploadURL); err != nil {
+		if err = r.tfclient.uploadConfiguration(configVersion.UploadURL, files); err != nil {
 			return true, err
 		}
 
@@ -395,12 +551,12 @@ func (r *WorkspaceHelper) prepareVCSRun(instance *appv1alpha1.Workspace) (bool,
 		instance.Spec.Organization, "Name", instance.Name, "Namespace", instance.Namespace)
 
 	configVersions, err := r.tfclient.Client.ConfigurationVersions.List(context.TODO(),
-		instance.Status.WorkspaceID, tfe.ConfigurationVersionListOptions{})
+		instance.Status.WorkspaceID, tfe.ConfigurationVersionListOptions{Include: tfe.String("ingress_attributes")})
 	if err != nil {
 		return false, err
 	}
 
-	if len(configVersions.Items) == 0 {
+	if len(configVersions.Items) == 0 || !vcsConfigurationReady(instance.Spec.VCS, configVersions.Items[0]) {
 		r.reqLogger.Info("ConfigVersion is not available yet", "Organization",
 			instance.Spec.Organization, "Name", instance.Name, "Namespace", instance.Namespace)
 		return true, nil
@@ -408,7 +564,9 @@ func (r *WorkspaceHelper) prepareVCSRun(instance *appv1alpha1.Workspace) (bool,
 	return false, nil
 }
 
-func (r *WorkspaceHelper) startRun(instance *appv1alpha1.Workspace) error {
+// startRun creates a run from the current configuration and returns false when the configuration
+// is not ready yet, in which case no run was created
+func (r *WorkspaceHelper) startRun(instance *appv1alpha1.Workspace, source appv1alpha1.RunSource) (bool, error) {
 	message := fmt.Sprintf("%s, apply", TerraformOperator)
 	options := tfe.RunCreateOptions{
 		Message: &message,
@@ -421,27 +579,31 @@ func (r *WorkspaceHelper) startRun(instance *appv1alpha1.Workspace) error {
 	requeue := false
 	if instance.Spec.VCS != nil {
 		requeue, err = r.prepareVCSRun(instance)
-	} else if instance.Spec.Module != nil {
-		requeue, err = r.prepareModuleRun(instance, options)
+	} else if generatesConfiguration(instance) {
+		requeue, err = r.prepareModuleRun(instance, &options)
 	}
 
 	if err != nil || requeue {
 		// When requeue is true it means that the workspace is not ready yet.
-		return err
+		return false, err
 	}
 
 	runResult, err := r.tfclient.Client.Runs.Create(context.TODO(), options)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	instance.Status.RunID = runResult.ID
 	instance.Status.RunStatus = string(runResult.Status)
+	r.recordRunFingerprint(instance)
+	instance.Status.ApprovalState = ""
+	instance.Status.VCSRunPending = false
+	r.recordRun(instance, runResult, source)
 	if err := r.client.Status().Update(context.TODO(), instance); err != nil {
 		r.reqLogger.Error(err, "Failed to update Workspace status")
-		return err
+		return true, err
 	}
-	return nil
+	return true, nil
 }
 
 func (r *WorkspaceHelper) reconcileNotifications(instance *appv1alpha1.Workspace) error {
@@ -461,13 +623,45 @@ func (r *WorkspaceHelper) reconcileNotifications(instance *appv1alpha1.Workspace
 func (r *WorkspaceHelper) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 	// Get instance, check if org and secrets exist or not
 	instance, err := r.initializeReconciliation(request)
-	if err != nil {
+	if instance == nil {
+		// Instance got garbage collected or could not be read
 		return reconcile.Result{}, err
-	} else if instance == nil {
-		// Instance got garbage collected
+	}
+
+	// Every phase records its outcome in the status conditions, which are
+	// persisted once the reconcile finishes.
+	previous := instance.Status.DeepCopy()
+	result := reconcile.Result{}
+	if err != nil {
+		err = phaseFailed(instance, reasonInitializationFailed, err)
+	} else {
+		result, err = r.reconcileInstance(instance)
+	}
+
+	if condErr := r.updateConditions(instance, previous); condErr != nil && err == nil {
+		return reconcile.Result{}, condErr
+	}
+	return result, err
+}
+
+// reconcileInstance runs the reconcile phases for a Workspace whose organization and secrets were validated
+func (r *WorkspaceHelper) reconcileInstance(instance *appv1alpha1.Workspace) (reconcile.Result, error) {
+	// A released Workspace no longer manages its Terraform Cloud workspace
+	if instance.Spec.Release {
+		if err := r.releaseWorkspace(instance); err != nil {
+			return reconcile.Result{}, phaseFailed(instance, reasonWorkspaceSyncFailed, err)
+		}
 		return reconcile.Result{}, nil
 	}
 
+	// A suspended Workspace only refreshes its status until it is resumed
+	suspended, err := r.reconcileSuspension(instance)
+	if err != nil {
+		return reconcile.Result{}, phaseFailed(instance, reasonMaintenanceCheckFailed, err)
+	} else if suspended {
+		return r.refreshSuspended(instance)
+	}
+
 	// Check if the object is pending deletion and act accordingly
 	deleted, err := r.condDeleteWorkspace(instance)
 	if err != nil {
@@ -479,11 +673,30 @@ func (r *WorkspaceHelper) Reconcile(ctx context.Context, request reconcile.Reque
 	// Create the workspace update instance with workspace information such as Workspace ID and Run ID.
 	err = r.reconcileWorkspace(instance)
 	if err != nil {
-		return reconcile.Result{}, err
+		return reconcile.Result{}, phaseFailed(instance, reasonWorkspaceSyncFailed, err)
 	}
 
 	// Check if notifications exist, create them if they don't
 	err = r.reconcileNotifications(instance)
+	if err != nil {
+		return reconcile.Result{}, phaseFailed(instance, reasonNotificationsSyncFailed, err)
+	}
+
+	// Tag the workspace from the spec and the labels
+	err = r.reconcileTags(instance)
+	if err != nil {
+		return reconcile.Result{}, phaseFailed(instance, reasonTagsSyncFailed, err)
+	}
+
+	// Grant the teams of the spec access to the workspace
+	err = r.reconcileTeamAccess(instance)
+	if err != nil {
+		return reconcile.Result{}, phaseFailed(instance, reasonTeamAccessSyncFailed, err)
+	}
+
+	// Read and discard a planned drift detection run first, since it holds the
+	// run queue of the workspace that the phases below wait on
+	err = r.refreshDriftCheck(instance)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
@@ -492,7 +705,24 @@ func (r *WorkspaceHelper) Reconcile(ctx context.Context, request reconcile.Reque
 	// returns instantly if the run is not in progress
 	shouldRequeue, err := r.runInProgress(instance)
 	if err != nil {
-		return reconcile.Result{}, err
+		return reconcile.Result{}, phaseFailed(instance, reasonRunStatusFailed, err)
+	}
+
+	// cancel or discard the run in progress when its configuration or variables changed since it was started
+	superseded := false
+	if shouldRequeue {
+		superseded, err = r.supersedeRun(instance)
+		if err != nil {
+			return reconcile.Result{}, phaseFailed(instance, reasonRunSupersedeFailed, err)
+		}
+		shouldRequeue = !superseded
+	}
+	setRunCondition(instance)
+	if shouldRequeue && (awaitingApproval(instance) || awaitingPolicyOverride(instance)) {
+		// Nothing changes until the run is approved, discarded or its policies
+		// overridden, which happens through an annotation that triggers a new
+		// reconcile anyway.
+		return reconcile.Result{RequeueAfter: requeueInterval}, nil
 	} else if shouldRequeue {
 		return reconcile.Result{Requeue: true}, nil
 	}
@@ -506,7 +736,17 @@ func (r *WorkspaceHelper) Reconcile(ctx context.Context, request reconcile.Reque
 	// Figure out if the terraform config has been updated for non VCS backed workspaces
 	updatedTerraform, err := r.updateTerraformTemplate(instance)
 	if err != nil {
-		return reconcile.Result{}, err
+		return reconcile.Result{}, phaseFailed(instance, reasonConfigurationFailed, err)
+	}
+
+	// Connect VCS backed workspaces to the repository of the spec, or disconnect
+	// them when they switched to a module
+	updatedVCS, err := r.updateVCSRepo(instance)
+	if err != nil {
+		return reconcile.Result{}, phaseFailed(instance, reasonConfigurationFailed, err)
+	}
+	if err := r.updateTagsRegex(instance); err != nil {
+		return reconcile.Result{}, phaseFailed(instance, reasonConfigurationFailed, err)
 	}
 
 	// make sure the variables in the tfc workspace match the ones in the workspace
@@ -515,25 +755,63 @@ func (r *WorkspaceHelper) Reconcile(ctx context.Context, request reconcile.Reque
 	// the k8s object is always the source of truth.
 	updatedVariables, err := r.updateVariables(instance)
 	if err != nil {
-		return reconcile.Result{}, err
+		return reconcile.Result{}, phaseFailed(instance, reasonVariablesSyncFailed, err)
 	}
 
 	// check that correct run triggers are configured to trigger the workspace
 	updatedRunTriggers, err := r.updateRunTriggers(instance)
 	if err != nil {
-		return reconcile.Result{}, err
+		return reconcile.Result{}, phaseFailed(instance, reasonRunTriggersSyncFailed, err)
+	}
+
+	// check if a run is due on the schedule
+	schedule, err := r.reconcileSchedule(instance)
+	if err != nil {
+		return reconcile.Result{}, phaseFailed(instance, reasonScheduleFailed, err)
 	}
+	scheduled := !schedule.due.IsZero()
 
-	if updatedTerraform || updatedVariables || updatedRunTriggers || instance.Status.RunID == "" || instance.Status.ConfigVersionID != "" {
-		err := r.startRun(instance)
+	if updatedTerraform || updatedVCS || updatedVariables || updatedRunTriggers || scheduled ||
+		(instance.Status.RunID == "" && !isAdopted(instance)) || instance.Status.ConfigVersionID != "" ||
+		instance.Status.VCSRunPending {
+		source := appv1alpha1.RunSourceSpecChange
+		if scheduled && !updatedTerraform && !updatedVCS && !updatedVariables && !updatedRunTriggers {
+			source = appv1alpha1.RunSourceSchedule
+		}
+		started, err := r.startRun(instance, source)
 		if err != nil {
-			return reconcile.Result{}, err
+			return reconcile.Result{}, phaseFailed(instance, reasonRunStartFailed, err)
+		} else if !started {
+			// The run starts once the configuration is ready, for example once
+			// the new repository or branch of a VCS workspace is ingressed
+			phasesSucceeded(instance)
+			return reconcile.Result{Requeue: true}, nil
+		}
+		if scheduled {
+			if err := r.recordScheduledRun(instance, schedule); err != nil {
+				return reconcile.Result{}, phaseFailed(instance, reasonScheduleFailed, err)
+			}
 		}
 
+		phasesSucceeded(instance)
+		setCondition(instance, appv1alpha1.ConditionRunSucceeded, metav1.ConditionUnknown, reasonRunStarted,
+			fmt.Sprintf("Started run %s", instance.Status.RunID))
 		r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent",
 			fmt.Sprintf("Started new Terraform job with id %s", instance.Status.RunID))
 		return reconcile.Result{Requeue: true}, nil
 	}
+	phasesSucceeded(instance)
+
+	// Check whether the real infrastructure drifted from the state on the
+	// configured interval, now that no run of the operator is in progress.
+	remediating, err := r.reconcileDrift(instance)
+	if err != nil {
+		return reconcile.Result{}, err
+	} else if remediating {
+		setCondition(instance, appv1alpha1.ConditionRunSucceeded, metav1.ConditionUnknown, reasonRunStarted,
+			fmt.Sprintf("Started run %s", instance.Status.RunID))
+		return reconcile.Result{Requeue: true}, nil
+	}
 
 	// We're asking the operator to reconcile again after a while because one of the following
 	// may happen:
@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDeletionPolicy(t *testing.T) {
+	tests := []struct {
+		name              string
+		existingWorkspace string
+		policy            v1alpha1.DeletionPolicy
+		want              v1alpha1.DeletionPolicy
+	}{
+		{name: "Created", want: v1alpha1.DeletionPolicyDestroy},
+		{name: "Adopted", existingWorkspace: "adopted", want: v1alpha1.DeletionPolicyOrphan},
+		{name: "Created with Retain", policy: v1alpha1.DeletionPolicyRetain, want: v1alpha1.DeletionPolicyRetain},
+		{name: "Created with Orphan", policy: v1alpha1.DeletionPolicyOrphan, want: v1alpha1.DeletionPolicyOrphan},
+		{name: "Adopted with Destroy", existingWorkspace: "adopted", policy: v1alpha1.DeletionPolicyDestroy,
+			want: v1alpha1.DeletionPolicyDestroy},
+		{name: "Adopted with Retain", existingWorkspace: "ws-abc123", policy: v1alpha1.DeletionPolicyRetain,
+			want: v1alpha1.DeletionPolicyRetain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspace := &v1alpha1.Workspace{Spec: v1alpha1.WorkspaceSpec{
+				ExistingWorkspace: tt.existingWorkspace,
+				DeletionPolicy:    tt.policy,
+			}}
+			assert.Equal(t, tt.want, deletionPolicy(workspace))
+		})
+	}
+}
+
+func TestFinalizeWorkspace(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      v1alpha1.DeletionPolicy
+		wantDeleted bool
+		wantEvent   string
+	}{
+		{name: "Destroy", policy: v1alpha1.DeletionPolicyDestroy, wantDeleted: true,
+			wantEvent: "Destroying resources in workspace ws-abc123"},
+		{name: "Retain", policy: v1alpha1.DeletionPolicyRetain, wantDeleted: true,
+			wantEvent: "Retaining resources of workspace ws-abc123"},
+		{name: "Orphan", policy: v1alpha1.DeletionPolicyOrphan,
+			wantEvent: "Orphaned workspace ws-abc123 and its resources"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				w.Header().Set("Content-Type", "application/vnd.api+json")
+				switch {
+				case r.Method == http.MethodDelete:
+					w.WriteHeader(http.StatusNoContent)
+				case strings.HasSuffix(r.URL.Path, "/runs"):
+					w.Write([]byte(`{"data": []}`))
+				default:
+					w.Write([]byte(`{"data": {"id": "ws-abc123", "type": "workspaces", "attributes": {"name": "deleted"}}}`))
+				}
+			}))
+			defer srv.Close()
+			client, err := tfe.NewClient(&tfe.Config{
+				Address:    srv.URL,
+				Token:      "token1",
+				HTTPClient: srv.Client(),
+			})
+			assert.NoError(t, err)
+
+			recorder := record.NewFakeRecorder(10)
+			r := &WorkspaceHelper{
+				tfclient:  &TerraformCloudClient{Client: client},
+				reqLogger: log,
+				recorder:  recorder,
+			}
+			workspace := &v1alpha1.Workspace{Spec: v1alpha1.WorkspaceSpec{DeletionPolicy: tt.policy}}
+			workspace.Status.WorkspaceID = "ws-abc123"
+			assert.NoError(t, r.finalizeWorkspace(log, workspace))
+
+			if tt.wantDeleted {
+				assert.Contains(t, requests, "DELETE /api/v2/workspaces/ws-abc123")
+			} else {
+				assert.NotContains(t, requests, "DELETE /api/v2/workspaces/ws-abc123")
+				assert.NotContains(t, requests, "GET /api/v2/workspaces/ws-abc123/runs")
+			}
+			assert.NotContains(t, requests, "POST /api/v2/runs")
+			assert.Contains(t, <-recorder.Events, tt.wantEvent)
+		})
+	}
+}
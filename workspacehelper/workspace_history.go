@@ -0,0 +1,151 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tfc "github.com/hashicorp/go-tfe"
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const defaultRunHistoryLimit = 10
+
+func runHistoryLimit(instance *appv1alpha1.Workspace) int {
+	if instance.Spec.RunHistoryLimit == nil {
+		return defaultRunHistoryLimit
+	}
+	return int(*instance.Spec.RunHistoryLimit)
+}
+
+// runURL links to a run in the Terraform Cloud or Enterprise UI
+func runURL(address, organization, workspace, runID string) string {
+	if address == "" {
+		address = tfc.DefaultAddress
+	}
+	return fmt.Sprintf("%s/app/%s/workspaces/%s/runs/%s", strings.TrimSuffix(address, "/"), organization, workspace, runID)
+}
+
+// runSource maps the source reported by Terraform Cloud for runs the operator did not start
+func runSource(run *tfc.Run) appv1alpha1.RunSource {
+	switch run.Source {
+	case tfc.RunSourceUI:
+		return appv1alpha1.RunSourceUI
+	case tfc.RunSourceConfigurationVersion:
+		return appv1alpha1.RunSourceVCS
+	default:
+		return appv1alpha1.RunSourceAPI
+	}
+}
+
+// recordRun adds a run to the front of the run history and drops the oldest runs beyond the limit
+func (r *WorkspaceHelper) recordRun(instance *appv1alpha1.Workspace, run *tfc.Run, source appv1alpha1.RunSource) {
+	entry := appv1alpha1.RunHistoryEntry{
+		ID:      run.ID,
+		Status:  string(run.Status),
+		Source:  source,
+		Message: run.Message,
+		URL:     runURL(r.tfclient.Address, instance.Spec.Organization, instance.Status.WorkspaceName, run.ID),
+	}
+	if !run.CreatedAt.IsZero() {
+		createdAt := metav1.NewTime(run.CreatedAt)
+		entry.CreatedAt = &createdAt
+	}
+	addRunHistory(instance, entry)
+}
+
+func addRunHistory(instance *appv1alpha1.Workspace, entry appv1alpha1.RunHistoryEntry) {
+	history := []appv1alpha1.RunHistoryEntry{entry}
+	for _, previous := range instance.Status.RunHistory {
+		if previous.ID != entry.ID {
+			history = append(history, previous)
+		}
+	}
+	if limit := runHistoryLimit(instance); len(history) > limit {
+		history = history[:limit]
+	}
+	instance.Status.RunHistory = history
+}
+
+// setRunHistoryStatus updates the status of a run in the run history
+func setRunHistoryStatus(instance *appv1alpha1.Workspace, runID, status string, now time.Time) {
+	for i := range instance.Status.RunHistory {
+		entry := &instance.Status.RunHistory[i]
+		if entry.ID != runID {
+			continue
+		}
+		entry.Status = status
+		if !isPending(status) && entry.FinishedAt == nil {
+			finishedAt := metav1.NewTime(now)
+			entry.FinishedAt = &finishedAt
+		}
+		return
+	}
+}
+
+// runObjectRunRecorded reports whether a run created for a Run object is in the run history and finished
+func runObjectRunRecorded(instance *appv1alpha1.Workspace, runID string) bool {
+	for _, entry := range instance.Status.RunHistory {
+		if entry.ID == runID {
+			return entry.Source == appv1alpha1.RunSourceRunObject && !isPending(entry.Status)
+		}
+	}
+	return false
+}
+
+// runObjectRunState reports whether a run belongs to one of the Runs of the Workspace, and whether
+// a Run is still creating its run, in which case the run cannot be told apart yet
+func runObjectRunState(instance *appv1alpha1.Workspace, runs []appv1alpha1.Run, runID string) (found, creating bool) {
+	for _, run := range runs {
+		if run.Spec.WorkspaceRef.Name != instance.Name {
+			continue
+		}
+		if run.Status.RunID == runID {
+			return true, false
+		}
+		if run.Status.RunID == "" && run.Status.WorkspaceID != "" {
+			creating = true
+		}
+	}
+	return false, creating
+}
+
+// findRunObjectRun looks up the Runs of the Workspace for the run, see runObjectRunState
+func (r *WorkspaceHelper) findRunObjectRun(instance *appv1alpha1.Workspace, runID string) (found, creating bool, err error) {
+	runs := &appv1alpha1.RunList{}
+	if err := r.client.List(context.TODO(), runs, client.InNamespace(instance.Namespace)); err != nil {
+		return false, false, err
+	}
+	found, creating = runObjectRunState(instance, runs.Items, runID)
+	return found, creating, nil
+}
+
+// recordRunObjectRun keeps a run created for a Run object in the run history without making it the
+// current run of the Workspace, the Run controller owns its lifecycle
+func (r *WorkspaceHelper) recordRunObjectRun(instance *appv1alpha1.Workspace, run *tfc.Run) error {
+	recorded := false
+	for _, entry := range instance.Status.RunHistory {
+		if entry.ID == run.ID {
+			recorded = true
+			if entry.Status == string(run.Status) {
+				return nil
+			}
+		}
+	}
+	if recorded {
+		setRunHistoryStatus(instance, run.ID, string(run.Status), time.Now())
+	} else {
+		r.recordRun(instance, run, appv1alpha1.RunSourceRunObject)
+	}
+	if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+		r.reqLogger.Error(err, "Failed to update run history")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	tfc "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunHistoryKeepsNewestRunsUpToLimit(t *testing.T) {
+	limit := int32(2)
+	workspace := &v1alpha1.Workspace{
+		Spec: v1alpha1.WorkspaceSpec{RunHistoryLimit: &limit},
+	}
+	for _, id := range []string{"run-1", "run-2", "run-3"} {
+		addRunHistory(workspace, v1alpha1.RunHistoryEntry{ID: id, Status: "pending"})
+	}
+	assert.Len(t, workspace.Status.RunHistory, 2)
+	assert.Equal(t, "run-3", workspace.Status.RunHistory[0].ID)
+	assert.Equal(t, "run-2", workspace.Status.RunHistory[1].ID)
+}
+
+func TestRunHistoryDefaultLimit(t *testing.T) {
+	workspace := &v1alpha1.Workspace{}
+	for i := 0; i < 15; i++ {
+		addRunHistory(workspace, v1alpha1.RunHistoryEntry{ID: fmt.Sprintf("run-%d", i)})
+	}
+	assert.Len(t, workspace.Status.RunHistory, defaultRunHistoryLimit)
+}
+
+func TestRunHistoryDoesNotDuplicateRuns(t *testing.T) {
+	workspace := &v1alpha1.Workspace{}
+	addRunHistory(workspace, v1alpha1.RunHistoryEntry{ID: "run-1", Status: "pending"})
+	addRunHistory(workspace, v1alpha1.RunHistoryEntry{ID: "run-1", Status: "planning"})
+	assert.Len(t, workspace.Status.RunHistory, 1)
+	assert.Equal(t, "planning", workspace.Status.RunHistory[0].Status)
+}
+
+func TestSetRunHistoryStatus(t *testing.T) {
+	workspace := &v1alpha1.Workspace{}
+	addRunHistory(workspace, v1alpha1.RunHistoryEntry{ID: "run-1", Status: "pending"})
+
+	setRunHistoryStatus(workspace, "run-1", "applying", time.Now())
+	assert.Equal(t, "applying", workspace.Status.RunHistory[0].Status)
+	assert.Nil(t, workspace.Status.RunHistory[0].FinishedAt)
+
+	setRunHistoryStatus(workspace, "run-1", "applied", time.Now())
+	assert.Equal(t, "applied", workspace.Status.RunHistory[0].Status)
+	assert.NotNil(t, workspace.Status.RunHistory[0].FinishedAt)
+}
+
+func TestRunURL(t *testing.T) {
+	assert.Equal(t, "https://app.terraform.io/app/hashicorp/workspaces/default-demo/runs/run-123",
+		runURL("", "hashicorp", "default-demo", "run-123"))
+	assert.Equal(t, "https://tfe.example.com/app/hashicorp/workspaces/default-demo/runs/run-123",
+		runURL("https://tfe.example.com/", "hashicorp", "default-demo", "run-123"))
+}
+
+func TestRunSource(t *testing.T) {
+	assert.Equal(t, v1alpha1.RunSourceUI, runSource(&tfc.Run{Source: tfc.RunSourceUI}))
+	assert.Equal(t, v1alpha1.RunSourceVCS, runSource(&tfc.Run{Source: tfc.RunSourceConfigurationVersion}))
+	assert.Equal(t, v1alpha1.RunSourceAPI, runSource(&tfc.Run{Source: tfc.RunSourceAPI}))
+}
@@ -0,0 +1,123 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+)
+
+// Keys of the run logs ConfigMap
+const (
+	logsRunIDKey     = "runID"
+	logsRunStatusKey = "runStatus"
+	logsPlanKey      = "plan.log"
+	logsApplyKey     = "apply.log"
+	logsErrorKey     = "error"
+)
+
+// mountedSecretValues returns the values of the secrets in the secrets mount path, including the ones
+// no variable of the spec refers to. Kubernetes links the keys of a mounted Secret to hidden ..data
+// directories, which are skipped.
+func mountedSecretValues(secretsMountPath string) ([]string, error) {
+	files, err := ioutil.ReadDir(secretsMountPath)
+	if err != nil {
+		return nil, err
+	}
+	values := []string{}
+	for _, file := range files {
+		if strings.HasPrefix(file.Name(), "..") {
+			continue
+		}
+		path := filepath.Join(secretsMountPath, file.Name())
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, string(data))
+		if trimmed := strings.TrimSpace(string(data)); trimmed != string(data) {
+			values = append(values, trimmed)
+		}
+	}
+	return values, nil
+}
+
+// sensitiveValues returns the values to redact from logs: the sensitive variables of the spec and
+// every secret in the secrets mount path. Sensitive variables set in Terraform Cloud cannot be read
+// back and are not redacted.
+func (r *WorkspaceHelper) sensitiveValues(instance *appv1alpha1.Workspace) []string {
+	values, err := mountedSecretValues(instance.Spec.SecretsMountPath)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not read secrets to redact", "SecretsMountPath", instance.Spec.SecretsMountPath)
+		values = []string{}
+	}
+	for _, variable := range instance.Spec.Variables {
+		if !variable.Sensitive {
+			continue
+		}
+		if variable.Value == "" && variable.ValueFrom != nil {
+			if err := r.GetSecretForVariable(instance.Namespace, variable); err != nil {
+				r.reqLogger.Error(err, "Could not read sensitive variable to redact", "Variable", variable.Key)
+			}
+		}
+		if variable.Value != "" {
+			values = append(values, variable.Value)
+		}
+	}
+	return values
+}
+
+// captureRunLogs stores the tail of the plan and apply logs of the finished
+// run in the <name>-logs ConfigMap and returns the summary of its errors.
+func (r *WorkspaceHelper) captureRunLogs(instance *appv1alpha1.Workspace) (string, error) {
+	if instance.Status.RunID == "" {
+		return "", nil
+	}
+	existing, err := r.GetRunLogsConfigMap(instance)
+	if err != nil {
+		return "", err
+	}
+	if existing[logsRunIDKey] == instance.Status.RunID && existing[logsRunStatusKey] == instance.Status.RunStatus {
+		return existing[logsErrorKey], nil
+	}
+
+	r.reqLogger.Info("Capturing run logs", "Organization", instance.Spec.Organization,
+		"RunID", instance.Status.RunID)
+	logs, err := r.tfclient.GetRunLogs(instance.Status.RunID)
+	if err != nil {
+		return "", err
+	}
+
+	sensitive := r.sensitiveValues(instance)
+	plan, short := redactSensitiveValues(logs.Plan, sensitive)
+	apply, _ := redactSensitiveValues(logs.Apply, sensitive)
+	plan, apply = tailLines(plan, logTailLines), tailLines(apply, logTailLines)
+	if short > 0 {
+		r.reqLogger.Info("Sensitive values too short to redact are left in the run logs",
+			"RunID", instance.Status.RunID, "Count", short, "MinLength", minRedactedLength)
+	}
+	summary := errorSummary(apply)
+	if summary == "" {
+		summary = errorSummary(plan)
+	}
+
+	data := map[string]string{
+		logsRunIDKey:     instance.Status.RunID,
+		logsRunStatusKey: instance.Status.RunStatus,
+		logsPlanKey:      plan,
+		logsApplyKey:     apply,
+		logsErrorKey:     summary,
+	}
+	if err := r.UpsertRunLogs(instance, data); err != nil {
+		return "", err
+	}
+	return summary, nil
+}
@@ -0,0 +1,35 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMountedSecretValues(t *testing.T) {
+	// Lay the directory out like a mounted Secret, with the keys linked to a ..data directory
+	directory, err := ioutil.TempDir("", "secrets-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(directory)
+	data := filepath.Join(directory, "..2021_06_01_10_00_00.000000000")
+	assert.NoError(t, os.Mkdir(data, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(data, "password"), []byte("hunter2\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(data, "AWS_SECRET_ACCESS_KEY"), []byte("abc123"), 0644))
+	assert.NoError(t, os.Symlink(filepath.Base(data), filepath.Join(directory, "..data")))
+	for _, key := range []string{"password", "AWS_SECRET_ACCESS_KEY"} {
+		assert.NoError(t, os.Symlink(filepath.Join("..data", key), filepath.Join(directory, key)))
+	}
+
+	values, err := mountedSecretValues(directory)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"hunter2\n", "hunter2", "abc123"}, values)
+
+	_, err = mountedSecretValues(filepath.Join(directory, "missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,184 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"text/template"
+
+	tfc "github.com/hashicorp/go-tfe"
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition reasons of the NameSynced condition
+const (
+	reasonNameMatches  = "NameMatches"
+	reasonNameMismatch = "NameMismatch"
+	reasonRenameFailed = "RenameFailed"
+)
+
+// DefaultWorkspaceNameTemplate names workspaces after the namespace and name of the Workspace
+const DefaultWorkspaceNameTemplate = "{{.Namespace}}-{{.Name}}"
+
+// Terraform Cloud workspace names are limited to letters, numbers, - and _
+var validWorkspaceName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,90}$`)
+
+// WorkspaceNameData is the data of the workspace name template
+type WorkspaceNameData struct {
+	// ClusterName is the name of the cluster given to the operator
+	ClusterName string
+	// Namespace of the Workspace
+	Namespace string
+	// Name of the Workspace
+	Name string
+}
+
+// ParseWorkspaceNameTemplate parses a workspace name template and checks it only uses the fields of WorkspaceNameData
+func ParseWorkspaceNameTemplate(text string) (*template.Template, error) {
+	tpl, err := template.New("workspace-name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var name bytes.Buffer
+	if err := tpl.Execute(&name, WorkspaceNameData{}); err != nil {
+		return nil, err
+	}
+	return tpl, nil
+}
+
+// workspaceName returns the name of the Terraform Cloud workspace of a Workspace: the name set in the spec,
+// the name of the Workspace when the namespace prefix is omitted, or the name template of the operator
+func workspaceName(instance *appv1alpha1.Workspace, options Options) (string, error) {
+	var name string
+	switch {
+	case instance.Spec.WorkspaceName != "":
+		name = instance.Spec.WorkspaceName
+	case instance.Spec.OmitNamespacePrefix:
+		name = instance.Name
+	default:
+		tpl := options.WorkspaceNameTemplate
+		if tpl == nil {
+			tpl = template.Must(ParseWorkspaceNameTemplate(DefaultWorkspaceNameTemplate))
+		}
+		var buf bytes.Buffer
+		data := WorkspaceNameData{ClusterName: options.ClusterName, Namespace: instance.Namespace, Name: instance.Name}
+		if err := tpl.Execute(&buf, data); err != nil {
+			return "", fmt.Errorf("could not render workspace name: %v", err)
+		}
+		name = buf.String()
+	}
+	if !validWorkspaceName.MatchString(name) {
+		return "", fmt.Errorf("workspace name %q is not valid, it needs 1 to 90 letters, numbers, - or _", name)
+	}
+	return name, nil
+}
+
+// claimedName returns the name of the Terraform Cloud workspace another Workspace claims
+func claimedName(workspace *appv1alpha1.Workspace, options Options) string {
+	if workspace.Status.WorkspaceName != "" {
+		return workspace.Status.WorkspaceName
+	} else if isAdopted(workspace) {
+		return ""
+	}
+	name, _ := workspaceName(workspace, options)
+	return name
+}
+
+// nameOwner returns the Workspace that owns the name of a Terraform Cloud workspace claimed by the instance:
+// the Workspace that is bound to it already, or else the oldest Workspace claiming it
+func nameOwner(instance *appv1alpha1.Workspace, name string, workspaces []appv1alpha1.Workspace, options Options) *appv1alpha1.Workspace {
+	for i := range workspaces {
+		other := &workspaces[i]
+		if other.UID == instance.UID || other.Spec.Organization != instance.Spec.Organization ||
+			claimedName(other, options) != name {
+			continue
+		}
+		if other.Status.WorkspaceID != "" {
+			return other
+		}
+		created, otherCreated := instance.CreationTimestamp, other.CreationTimestamp
+		if otherCreated.Before(&created) || (otherCreated.Equal(&created) &&
+			other.Namespace+"/"+other.Name < instance.Namespace+"/"+instance.Name) {
+			return other
+		}
+	}
+	return nil
+}
+
+// checkNameCollision makes sure that the workspace name of the instance is not used by another Workspace or by a
+// workspace that was not created for the instance, before a workspace of that name is created or bound
+func (r *WorkspaceHelper) checkNameCollision(instance *appv1alpha1.Workspace, name string) error {
+	if isAdopted(instance) || instance.Status.WorkspaceName == name {
+		return nil
+	}
+	// A bound workspace keeps its name unless it is renamed to the desired one
+	bound := instance.Status.WorkspaceID != ""
+	if bound && !instance.Spec.EnforceName {
+		return nil
+	}
+
+	workspaces := &appv1alpha1.WorkspaceList{}
+	if err := r.client.List(context.TODO(), workspaces); err != nil {
+		r.reqLogger.Error(err, "Could not list Workspaces")
+		return err
+	}
+	if owner := nameOwner(instance, name, workspaces.Items, r.options); owner != nil {
+		err := fmt.Errorf("workspace name %s is already used by Workspace %s/%s", name, owner.Namespace, owner.Name)
+		r.recorder.Event(instance, corev1.EventTypeWarning, "WorkspaceEvent", err.Error())
+		return err
+	}
+
+	ws, err := r.tfclient.Client.Workspaces.Read(context.TODO(), instance.Spec.Organization, name)
+	if err == tfc.ErrResourceNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	// A workspace created before the Workspace belongs to someone else, while a later one
+	// was created by the operator before it could record its ID
+	if ws.ID != instance.Status.WorkspaceID && (bound || ws.CreatedAt.Before(instance.CreationTimestamp.Time)) {
+		err := fmt.Errorf("workspace %s (%s) already exists in organization %s, set existingWorkspace to adopt it or choose another name",
+			name, ws.ID, instance.Spec.Organization)
+		r.recorder.Event(instance, corev1.EventTypeWarning, "WorkspaceEvent", err.Error())
+		return err
+	}
+	return nil
+}
+
+// reconcileWorkspaceName reports whether the workspace has its desired name in the NameSynced condition,
+// and renames it to the desired name when the spec enforces it
+func (r *WorkspaceHelper) reconcileWorkspaceName(instance *appv1alpha1.Workspace, ws *tfc.Workspace, desired string) error {
+	if isAdopted(instance) {
+		meta.RemoveStatusCondition(&instance.Status.Conditions, appv1alpha1.ConditionNameSynced)
+		return nil
+	}
+
+	if ws.Name != desired && instance.Spec.EnforceName {
+		renamed, err := r.tfclient.RenameWorkspace(ws.ID, desired)
+		if err != nil {
+			r.reqLogger.Error(err, "Could not rename workspace", "WorkspaceID", ws.ID, "Name", desired)
+			setCondition(instance, appv1alpha1.ConditionNameSynced, metav1.ConditionFalse, reasonRenameFailed,
+				fmt.Sprintf("Could not rename workspace %s from %s to %s: %v", ws.ID, ws.Name, desired, err))
+			return err
+		}
+		r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent",
+			fmt.Sprintf("Renamed workspace %s from %s to %s", ws.ID, ws.Name, renamed.Name))
+		ws.Name = renamed.Name
+	}
+
+	if ws.Name != desired {
+		setCondition(instance, appv1alpha1.ConditionNameSynced, metav1.ConditionFalse, reasonNameMismatch,
+			fmt.Sprintf("Workspace %s is named %s instead of %s", ws.ID, ws.Name, desired))
+		return nil
+	}
+	setCondition(instance, appv1alpha1.ConditionNameSynced, metav1.ConditionTrue, reasonNameMatches,
+		fmt.Sprintf("Workspace %s is named %s", ws.ID, ws.Name))
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	tfc "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestParseWorkspaceNameTemplate(t *testing.T) {
+	_, err := ParseWorkspaceNameTemplate("{{.ClusterName}}-{{.Namespace}}-{{.Name}}")
+	assert.NoError(t, err)
+	_, err = ParseWorkspaceNameTemplate("{{.Cluster}}-{{.Name}}")
+	assert.Error(t, err, "unknown fields are rejected at startup")
+	_, err = ParseWorkspaceNameTemplate("{{.Name")
+	assert.Error(t, err)
+}
+
+func TestWorkspaceName(t *testing.T) {
+	workspace := &v1alpha1.Workspace{ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: "prod"}}
+
+	name, err := workspaceName(workspace, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, "prod-hello", name)
+
+	tpl, err := ParseWorkspaceNameTemplate("{{.ClusterName}}-{{.Namespace}}-{{.Name}}")
+	assert.NoError(t, err)
+	options := Options{WorkspaceNameTemplate: tpl, ClusterName: "eu1"}
+	name, err = workspaceName(workspace, options)
+	assert.NoError(t, err)
+	assert.Equal(t, "eu1-prod-hello", name)
+
+	workspace.Spec.OmitNamespacePrefix = true
+	name, err = workspaceName(workspace, options)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", name)
+
+	workspace.Spec.WorkspaceName = "payments"
+	name, err = workspaceName(workspace, options)
+	assert.NoError(t, err)
+	assert.Equal(t, "payments", name)
+
+	tpl, err = ParseWorkspaceNameTemplate("{{.ClusterName}}/{{.Name}}")
+	assert.NoError(t, err)
+	workspace.Spec = v1alpha1.WorkspaceSpec{}
+	_, err = workspaceName(workspace, Options{WorkspaceNameTemplate: tpl})
+	assert.Error(t, err, "names with / are rejected")
+}
+
+func TestNameOwner(t *testing.T) {
+	now := time.Now()
+	newWorkspace := func(uid, namespace string, created time.Time) v1alpha1.Workspace {
+		return v1alpha1.Workspace{
+			ObjectMeta: metav1.ObjectMeta{
+				UID:               types.UID(uid),
+				Name:              "hello",
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: v1alpha1.WorkspaceSpec{Organization: "world", OmitNamespacePrefix: true},
+		}
+	}
+	instance := newWorkspace("1", "prod", now)
+
+	older := newWorkspace("2", "dev", now.Add(-time.Hour))
+	assert.Equal(t, "dev", nameOwner(&instance, "hello", []v1alpha1.Workspace{instance, older}, Options{}).Namespace)
+
+	newer := newWorkspace("2", "dev", now.Add(time.Hour))
+	assert.Nil(t, nameOwner(&instance, "hello", []v1alpha1.Workspace{instance, newer}, Options{}))
+
+	newer.Status.WorkspaceID = "ws-123"
+	newer.Status.WorkspaceName = "hello"
+	assert.NotNil(t, nameOwner(&instance, "hello", []v1alpha1.Workspace{newer}, Options{}), "bound Workspaces own their name")
+
+	otherOrg := newWorkspace("2", "dev", now.Add(-time.Hour))
+	otherOrg.Spec.Organization = "mars"
+	assert.Nil(t, nameOwner(&instance, "hello", []v1alpha1.Workspace{otherOrg}, Options{}))
+
+	prefixed := newWorkspace("2", "dev", now.Add(-time.Hour))
+	prefixed.Spec.OmitNamespacePrefix = false
+	assert.Nil(t, nameOwner(&instance, "hello", []v1alpha1.Workspace{prefixed}, Options{}))
+}
+
+func TestReconcileWorkspaceName(t *testing.T) {
+	var renamedTo string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.Method != http.MethodPatch {
+			return
+		}
+		var body struct {
+			Data struct {
+				Attributes struct {
+					Name string `json:"name"`
+				} `json:"attributes"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		renamedTo = body.Data.Attributes.Name
+		fmt.Fprintf(w, `{"data": {"id": "ws-abc123", "type": "workspaces", "attributes": {"name": "%s"}}}`, renamedTo)
+	}))
+	defer srv.Close()
+	client, err := tfc.NewClient(&tfc.Config{Address: srv.URL, Token: "token1", HTTPClient: srv.Client()})
+	assert.NoError(t, err)
+	r := &WorkspaceHelper{
+		tfclient:  &TerraformCloudClient{Client: client},
+		reqLogger: log,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	instance := &v1alpha1.Workspace{ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: "prod"}}
+	ws := &tfc.Workspace{ID: "ws-abc123", Name: "renamed-in-ui"}
+	assert.NoError(t, r.reconcileWorkspaceName(instance, ws, "prod-hello"))
+	condition := meta.FindStatusCondition(instance.Status.Conditions, v1alpha1.ConditionNameSynced)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, reasonNameMismatch, condition.Reason)
+	assert.Empty(t, renamedTo, "the workspace is only renamed when the spec enforces its name")
+
+	instance.Spec.EnforceName = true
+	assert.NoError(t, r.reconcileWorkspaceName(instance, ws, "prod-hello"))
+	assert.Equal(t, "prod-hello", renamedTo)
+	assert.Equal(t, "prod-hello", ws.Name)
+	condition = meta.FindStatusCondition(instance.Status.Conditions, v1alpha1.ConditionNameSynced)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+
+	instance.Spec.ExistingWorkspace = "ws-abc123"
+	assert.NoError(t, r.reconcileWorkspaceName(instance, ws, "prod-hello"))
+	assert.Nil(t, meta.FindStatusCondition(instance.Status.Conditions, v1alpha1.ConditionNameSynced))
+}
@@ -0,0 +1,174 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	tfc "github.com/hashicorp/go-tfe"
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// awaitingPolicyOverride reports whether the current run failed soft-mandatory policies and waits for an override
+func awaitingPolicyOverride(instance *appv1alpha1.Workspace) bool {
+	return tfc.RunStatus(instance.Status.RunStatus) == tfc.RunPolicySoftFailed
+}
+
+// policyCheckOutdated reports whether the policy checks in the status need to be read again for the current run
+func policyCheckOutdated(instance *appv1alpha1.Workspace) bool {
+	switch tfc.RunStatus(instance.Status.RunStatus) {
+	case tfc.RunPolicyChecked, tfc.RunPolicySoftFailed, tfc.RunPolicyOverride, tfc.RunErrored:
+	default:
+		return false
+	}
+	check := instance.Status.PolicyCheck
+	if check == nil || check.RunID != instance.Status.RunID {
+		return true
+	}
+	return tfc.RunStatus(instance.Status.RunStatus) == tfc.RunPolicyOverride &&
+		check.Status != string(tfc.PolicyOverridden)
+}
+
+// failedPolicies lists the policies that failed with their enforcement level
+func failedPolicies(check *appv1alpha1.PolicyCheckStatus) []string {
+	failed := []string{}
+	for _, policy := range check.Policies {
+		if !policy.Passed {
+			failed = append(failed, fmt.Sprintf("%s (%s)", policy.Name, policy.EnforcementLevel))
+		}
+	}
+	return failed
+}
+
+// updatePolicyCheck records the policy checks of the current run once they are done
+func (r *WorkspaceHelper) updatePolicyCheck(instance *appv1alpha1.Workspace) error {
+	if !policyCheckOutdated(instance) {
+		return nil
+	}
+
+	runID := instance.Status.RunID
+	check, err := r.tfclient.GetPolicyChecks(runID)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not get policy checks of run", "RunID", runID)
+		return err
+	}
+	if check == nil {
+		if instance.Status.PolicyCheck == nil {
+			return nil
+		}
+	} else if previous := instance.Status.PolicyCheck; previous != nil && previous.RunID == runID {
+		check.OverriddenBy = previous.OverriddenBy
+	}
+	instance.Status.PolicyCheck = check
+	if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+		r.reqLogger.Error(err, "Failed to update policy checks")
+		return err
+	}
+
+	if check == nil || check.Status == string(tfc.PolicyOverridden) {
+		return nil
+	}
+	if failed := failedPolicies(check); len(failed) > 0 {
+		msg := fmt.Sprintf("Run %s failed policies: %s", runID, strings.Join(failed, ", "))
+		if check.Status == string(tfc.PolicySoftFailed) {
+			msg = fmt.Sprintf("%s. Annotate the Workspace with %s=%s to override them",
+				msg, appv1alpha1.OverridePolicyAnnotation, runID)
+		}
+		r.recorder.Event(instance, corev1.EventTypeWarning, "WorkspaceEvent", msg)
+	}
+	return nil
+}
+
+// overrideManager returns the field manager that set the policy override annotation, as recorded
+// by the API server in the managed fields of the Workspace
+func overrideManager(instance *appv1alpha1.Workspace) string {
+	field := "f:" + appv1alpha1.OverridePolicyAnnotation
+	manager := ""
+	var latest *metav1.Time
+	for _, entry := range instance.GetManagedFields() {
+		if entry.FieldsV1 == nil {
+			continue
+		}
+		var fields struct {
+			Metadata struct {
+				Annotations map[string]json.RawMessage `json:"f:annotations"`
+			} `json:"f:metadata"`
+		}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		if _, ok := fields.Metadata.Annotations[field]; !ok {
+			continue
+		}
+		if manager == "" || (entry.Time != nil && (latest == nil || latest.Before(entry.Time))) {
+			manager = entry.Manager
+			latest = entry.Time
+		}
+	}
+	return manager
+}
+
+func (r *WorkspaceHelper) removePolicyOverrideAnnotations(instance *appv1alpha1.Workspace) error {
+	err := r.patchMetadata(instance, func(patched *appv1alpha1.Workspace) {
+		annotations := patched.GetAnnotations()
+		delete(annotations, appv1alpha1.OverridePolicyAnnotation)
+		patched.SetAnnotations(annotations)
+	})
+	if err != nil {
+		r.reqLogger.Error(err, "Failed to remove policy override annotations")
+		return err
+	}
+	return nil
+}
+
+// reconcilePolicyOverride overrides the soft-mandatory policies that failed
+// when the Workspace is annotated with the ID of the current run.
+func (r *WorkspaceHelper) reconcilePolicyOverride(instance *appv1alpha1.Workspace) error {
+	runID := instance.Status.RunID
+	annotations := instance.GetAnnotations()
+	overrideID := annotations[appv1alpha1.OverridePolicyAnnotation]
+	if overrideID == "" {
+		return nil
+	}
+	if overrideID != runID {
+		r.recorder.Event(instance, corev1.EventTypeWarning, "WorkspaceEvent",
+			fmt.Sprintf("Ignoring policy override for run %s, run %s is the one waiting for an override",
+				overrideID, runID))
+		return r.removePolicyOverrideAnnotations(instance)
+	}
+
+	manager := overrideManager(instance)
+	if err := r.tfclient.OverridePolicyChecks(runID); err != nil {
+		r.reqLogger.Error(err, "Could not override policy checks", "RunID", runID)
+		return err
+	}
+	failed := []string{}
+	if instance.Status.PolicyCheck != nil && instance.Status.PolicyCheck.RunID == runID {
+		instance.Status.PolicyCheck.OverriddenBy = manager
+		failed = failedPolicies(instance.Status.PolicyCheck)
+	}
+	msg := fmt.Sprintf("Policy checks of run %s overridden", runID)
+	if manager != "" {
+		msg = fmt.Sprintf("%s, the %s annotation was set by field manager %s",
+			msg, appv1alpha1.OverridePolicyAnnotation, manager)
+	}
+	r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent",
+		fmt.Sprintf("%s. Overridden policies: %s", msg, strings.Join(failed, ", ")))
+	r.reqLogger.Info("Overrode policy checks", "Organization", instance.Spec.Organization,
+		"RunID", runID, "FieldManager", manager)
+
+	if err := r.removePolicyOverrideAnnotations(instance); err != nil {
+		return err
+	}
+	if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+		r.reqLogger.Error(err, "Failed to update policy checks")
+		return err
+	}
+	return nil
+}
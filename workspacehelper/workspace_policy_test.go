@@ -0,0 +1,60 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPolicyCheckOutdated(t *testing.T) {
+	workspace := &v1alpha1.Workspace{
+		Status: v1alpha1.WorkspaceStatus{RunID: "run-1", RunStatus: "policy_checking"},
+	}
+	assert.False(t, policyCheckOutdated(workspace))
+
+	workspace.Status.RunStatus = "policy_soft_failed"
+	assert.True(t, policyCheckOutdated(workspace))
+
+	workspace.Status.PolicyCheck = &v1alpha1.PolicyCheckStatus{RunID: "run-1", Status: "soft_failed"}
+	assert.False(t, policyCheckOutdated(workspace))
+
+	workspace.Status.RunStatus = "policy_override"
+	assert.True(t, policyCheckOutdated(workspace))
+
+	workspace.Status.PolicyCheck.Status = "overridden"
+	assert.False(t, policyCheckOutdated(workspace))
+}
+
+func TestSoftFailedRunsWaitForPolicyOverride(t *testing.T) {
+	workspace := &v1alpha1.Workspace{
+		Status: v1alpha1.WorkspaceStatus{RunID: "run-1", RunStatus: "policy_soft_failed"},
+	}
+	assert.True(t, awaitingPolicyOverride(workspace))
+	assert.False(t, awaitingApproval(workspace))
+
+	workspace.Status.RunStatus = "policy_override"
+	assert.False(t, awaitingPolicyOverride(workspace))
+}
+
+func TestOverrideManager(t *testing.T) {
+	earlier := metav1.NewTime(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC))
+	later := metav1.NewTime(earlier.Add(time.Hour))
+	workspace := &v1alpha1.Workspace{}
+	assert.Equal(t, "", overrideManager(workspace))
+
+	workspace.ManagedFields = []metav1.ManagedFieldsEntry{
+		{Manager: "kubectl-client-side-apply", Time: &earlier, FieldsV1: &metav1.FieldsV1{
+			Raw: []byte(`{"f:metadata":{"f:annotations":{".":{},"f:kubectl.kubernetes.io/last-applied-configuration":{}}}}`)}},
+		{Manager: "terraform-k8s", Time: &later, FieldsV1: &metav1.FieldsV1{
+			Raw: []byte(`{"f:status":{"f:runID":{}}}`)}},
+		{Manager: "kubectl-annotate", Time: &earlier, FieldsV1: &metav1.FieldsV1{
+			Raw: []byte(`{"f:metadata":{"f:annotations":{"f:app.terraform.io/override-policy":{}}}}`)}},
+	}
+	assert.Equal(t, "kubectl-annotate", overrideManager(workspace))
+}
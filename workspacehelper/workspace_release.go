@@ -0,0 +1,60 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"fmt"
+
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition reason of the Suspended condition once the workspace is released
+const reasonReleased = "Released"
+
+// releaseBinding clears the status fields that bind the Workspace to its Terraform Cloud workspace
+func releaseBinding(status *appv1alpha1.WorkspaceStatus) {
+	status.WorkspaceID = ""
+	status.WorkspaceName = ""
+	status.RunID = ""
+	status.RunStatus = ""
+	status.RunFingerprint = ""
+	status.ConfigVersionID = ""
+	status.VCSRunPending = false
+	status.ApprovalState = ""
+	status.Tags = nil
+	status.TeamAccess = nil
+}
+
+// releaseWorkspace drops the binding to the Terraform Cloud workspace and the finalizer, leaving
+// the workspace and its resources untouched, so the Workspace can stay in the cluster or be
+// deleted without the finalizer running.
+func (r *WorkspaceHelper) releaseWorkspace(instance *appv1alpha1.Workspace) error {
+	if workspaceID := instance.Status.WorkspaceID; workspaceID != "" {
+		releaseBinding(&instance.Status)
+		if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+			r.reqLogger.Error(err, "Failed to release workspace")
+			return err
+		}
+		r.reqLogger.Info("Released workspace", "Organization", instance.Spec.Organization, "WorkspaceID", workspaceID)
+		r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent",
+			fmt.Sprintf("Released workspace %s back to unmanaged", workspaceID))
+	}
+
+	if contains(instance.GetFinalizers(), workspaceFinalizer) {
+		err := r.patchMetadata(instance, func(patched *appv1alpha1.Workspace) {
+			patched.SetFinalizers(remove(patched.GetFinalizers(), workspaceFinalizer))
+		})
+		if err != nil {
+			r.reqLogger.Error(err, "Failed to remove finalizer of released workspace")
+			return err
+		}
+	}
+
+	setCondition(instance, appv1alpha1.ConditionSuspended, metav1.ConditionTrue, reasonReleased,
+		"The workspace is released back to unmanaged by spec.release")
+	return nil
+}
@@ -0,0 +1,31 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseBindingKeepsHistory(t *testing.T) {
+	status := v1alpha1.WorkspaceStatus{
+		WorkspaceID:   "ws-abc123",
+		WorkspaceName: "adopted",
+		RunID:         "run-abc123",
+		RunStatus:     "applied",
+		Tags:          []string{"team:payments"},
+		Outputs:       []*v1alpha1.OutputStatus{{Key: "url", Value: "https://example.com"}},
+		RunHistory:    []v1alpha1.RunHistoryEntry{{ID: "run-abc123", Status: "applied"}},
+	}
+	releaseBinding(&status)
+	assert.Empty(t, status.WorkspaceID)
+	assert.Empty(t, status.WorkspaceName)
+	assert.Empty(t, status.RunID)
+	assert.Empty(t, status.RunStatus)
+	assert.Empty(t, status.Tags)
+	assert.Len(t, status.Outputs, 1)
+	assert.Len(t, status.RunHistory, 1)
+}
@@ -0,0 +1,153 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// scheduleStartingDeadline is how late a scheduled run may start before it
+// counts as missed and the catch-up policy applies
+const scheduleStartingDeadline = 5 * time.Minute
+
+// maxMissedSchedules bounds how many missed schedules are counted after a long downtime
+const maxMissedSchedules = 1000
+
+// scheduleResult is the outcome of evaluating a schedule at a point in time
+type scheduleResult struct {
+	// Scheduled time of the run to start, zero when no run is due
+	due time.Time
+	// Time of the next schedule after now
+	next time.Time
+	// Number of schedules that passed beyond their starting deadline
+	missed int
+}
+
+func parseSchedule(schedule *appv1alpha1.Schedule) (cron.Schedule, error) {
+	location := time.UTC
+	if schedule.TimeZone != "" {
+		loc, err := time.LoadLocation(schedule.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule time zone %q, %v", schedule.TimeZone, err)
+		}
+		location = loc
+	}
+	parsed, err := cron.ParseStandard(schedule.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q, %v", schedule.Cron, err)
+	}
+	if spec, ok := parsed.(*cron.SpecSchedule); ok {
+		spec.Location = location
+	}
+	return parsed, nil
+}
+
+// evaluateSchedule decides whether a scheduled run is due given the next
+// scheduled time recorded in the status.
+func evaluateSchedule(schedule *appv1alpha1.Schedule, recordedNext *metav1.Time, now time.Time) (scheduleResult, error) {
+	parsed, err := parseSchedule(schedule)
+	if err != nil {
+		return scheduleResult{}, err
+	}
+	result := scheduleResult{next: parsed.Next(now)}
+
+	// Nothing was scheduled yet, or the schedule changed since the next time was recorded
+	if recordedNext == nil || !parsed.Next(recordedNext.Add(-time.Second)).Equal(recordedNext.Time) {
+		return result, nil
+	}
+	if now.Before(recordedNext.Time) {
+		result.next = recordedNext.Time
+		return result, nil
+	}
+
+	latest := recordedNext.Time
+	passed := 1
+	for t := parsed.Next(latest); !t.After(now) && passed < maxMissedSchedules; t = parsed.Next(t) {
+		latest = t
+		passed++
+	}
+
+	if passed == 1 && now.Sub(latest) <= scheduleStartingDeadline {
+		result.due = latest
+		return result, nil
+	}
+	result.missed = passed
+	if schedule.CatchUpPolicy != appv1alpha1.CatchUpPolicySkip {
+		result.due = latest
+	}
+	return result, nil
+}
+
+// advanceSchedule records a scheduled run that was started and the time of the next one
+func advanceSchedule(status *appv1alpha1.WorkspaceStatus, result scheduleResult) {
+	last := metav1.NewTime(result.due)
+	next := metav1.NewTime(result.next)
+	status.LastScheduledTime = &last
+	status.NextScheduledTime = &next
+}
+
+// reconcileSchedule returns the schedule of the run that is due, if any. The next scheduled time is only
+// recorded in the status when no run is due; a due run is recorded by recordScheduledRun once it was
+// created, so it is retried when starting it fails.
+func (r *WorkspaceHelper) reconcileSchedule(instance *appv1alpha1.Workspace) (scheduleResult, error) {
+	if instance.Spec.Schedule == nil {
+		if instance.Status.NextScheduledTime == nil && instance.Status.LastScheduledTime == nil {
+			return scheduleResult{}, nil
+		}
+		instance.Status.NextScheduledTime = nil
+		instance.Status.LastScheduledTime = nil
+		return scheduleResult{}, r.updateScheduleStatus(instance)
+	}
+
+	result, err := evaluateSchedule(instance.Spec.Schedule, instance.Status.NextScheduledTime, time.Now())
+	if err != nil {
+		r.reqLogger.Error(err, "Could not evaluate schedule", "Schedule", instance.Spec.Schedule.Cron)
+		return scheduleResult{}, err
+	}
+	if !result.due.IsZero() {
+		r.reqLogger.Info("Scheduled run is due", "Schedule", instance.Spec.Schedule.Cron, "ScheduledTime", result.due)
+		return result, nil
+	}
+
+	if result.missed > 0 {
+		r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent",
+			fmt.Sprintf("Skipped %d missed scheduled runs", result.missed))
+	}
+	next := metav1.NewTime(result.next)
+	if instance.Status.NextScheduledTime != nil && instance.Status.NextScheduledTime.Equal(&next) {
+		return result, nil
+	}
+	instance.Status.NextScheduledTime = &next
+	return result, r.updateScheduleStatus(instance)
+}
+
+// recordScheduledRun advances the schedule once the run that was due is created
+func (r *WorkspaceHelper) recordScheduledRun(instance *appv1alpha1.Workspace, result scheduleResult) error {
+	advanceSchedule(&instance.Status, result)
+	if err := r.updateScheduleStatus(instance); err != nil {
+		return err
+	}
+	if result.missed > 0 {
+		r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent",
+			fmt.Sprintf("Caught up on %d missed scheduled runs with run %s", result.missed, instance.Status.RunID))
+	}
+	r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent",
+		fmt.Sprintf("Started run %s scheduled for %s", instance.Status.RunID, result.due.Format(time.RFC3339)))
+	return nil
+}
+
+func (r *WorkspaceHelper) updateScheduleStatus(instance *appv1alpha1.Workspace) error {
+	if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+		r.reqLogger.Error(err, "Failed to update schedule status")
+		return err
+	}
+	return nil
+}
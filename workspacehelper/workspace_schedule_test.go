@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func scheduledAt(value string) *metav1.Time {
+	t, _ := time.Parse(time.RFC3339, value)
+	scheduled := metav1.NewTime(t)
+	return &scheduled
+}
+
+func TestScheduleRecordsNextTimeWithoutRunning(t *testing.T) {
+	schedule := &v1alpha1.Schedule{Cron: "0 3 * * *"}
+	now := scheduledAt("2021-06-01T10:00:00Z").Time
+
+	result, err := evaluateSchedule(schedule, nil, now)
+	assert.NoError(t, err)
+	assert.True(t, result.due.IsZero())
+	assert.Equal(t, scheduledAt("2021-06-02T03:00:00Z").Time, result.next)
+}
+
+func TestScheduleRunsWhenDue(t *testing.T) {
+	schedule := &v1alpha1.Schedule{Cron: "0 3 * * *"}
+	next := scheduledAt("2021-06-02T03:00:00Z")
+
+	result, err := evaluateSchedule(schedule, next, next.Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, result.due.IsZero())
+
+	result, err = evaluateSchedule(schedule, next, next.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, next.Time, result.due)
+	assert.Equal(t, 0, result.missed)
+	assert.Equal(t, scheduledAt("2021-06-03T03:00:00Z").Time, result.next)
+}
+
+func TestScheduleUsesTimeZone(t *testing.T) {
+	schedule := &v1alpha1.Schedule{Cron: "0 3 * * *", TimeZone: "Europe/Paris"}
+	now := scheduledAt("2021-06-01T10:00:00Z").Time
+
+	result, err := evaluateSchedule(schedule, nil, now)
+	assert.NoError(t, err)
+	assert.True(t, scheduledAt("2021-06-02T01:00:00Z").Time.Equal(result.next))
+}
+
+func TestScheduleCatchUpPolicies(t *testing.T) {
+	next := scheduledAt("2021-06-02T03:00:00Z")
+	now := scheduledAt("2021-06-04T12:00:00Z").Time
+
+	result, err := evaluateSchedule(&v1alpha1.Schedule{Cron: "0 3 * * *"}, next, now)
+	assert.NoError(t, err)
+	assert.Equal(t, scheduledAt("2021-06-04T03:00:00Z").Time, result.due)
+	assert.Equal(t, 3, result.missed)
+
+	result, err = evaluateSchedule(&v1alpha1.Schedule{Cron: "0 3 * * *", CatchUpPolicy: v1alpha1.CatchUpPolicySkip}, next, now)
+	assert.NoError(t, err)
+	assert.True(t, result.due.IsZero())
+	assert.Equal(t, 3, result.missed)
+	assert.Equal(t, scheduledAt("2021-06-05T03:00:00Z").Time, result.next)
+}
+
+func TestScheduleIgnoresNextTimeOfPreviousSchedule(t *testing.T) {
+	schedule := &v1alpha1.Schedule{Cron: "30 4 * * *"}
+	next := scheduledAt("2021-06-02T03:00:00Z")
+
+	result, err := evaluateSchedule(schedule, next, next.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, result.due.IsZero())
+	assert.Equal(t, scheduledAt("2021-06-02T04:30:00Z").Time, result.next)
+}
+
+func TestScheduleWithInvalidCron(t *testing.T) {
+	_, err := evaluateSchedule(&v1alpha1.Schedule{Cron: "every day"}, nil, time.Now())
+	assert.Error(t, err)
+
+	_, err = evaluateSchedule(&v1alpha1.Schedule{Cron: "0 3 * * *", TimeZone: "Mars/Olympus"}, nil, time.Now())
+	assert.Error(t, err)
+}
+
+func TestScheduleStaysDueUntilRunIsRecorded(t *testing.T) {
+	schedule := &v1alpha1.Schedule{Cron: "0 3 * * *"}
+	status := v1alpha1.WorkspaceStatus{NextScheduledTime: scheduledAt("2021-06-02T03:00:00Z")}
+	due := scheduledAt("2021-06-02T03:00:00Z").Time
+
+	result, err := evaluateSchedule(schedule, status.NextScheduledTime, due.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, due, result.due)
+
+	// Starting the run failed, so the schedule was not advanced and the run is still due
+	result, err = evaluateSchedule(schedule, status.NextScheduledTime, due.Add(2*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, due, result.due)
+
+	advanceSchedule(&status, result)
+	assert.Equal(t, due, status.LastScheduledTime.Time)
+	result, err = evaluateSchedule(schedule, status.NextScheduledTime, due.Add(3*time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, result.due.IsZero())
+	assert.Equal(t, scheduledAt("2021-06-03T03:00:00Z").Time, result.next)
+}
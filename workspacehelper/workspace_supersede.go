@@ -0,0 +1,107 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// runFingerprint hashes what a run is started with: the Terraform configuration files, the VCS
+// repository and the variables of the spec. Other spec fields, like tags or the schedule, do not
+// change the run and are left out.
+func runFingerprint(instance *appv1alpha1.Workspace, files map[string]string) (string, error) {
+	data, err := json.Marshal(struct {
+		Files     map[string]string       `json:"files,omitempty"`
+		VCS       *appv1alpha1.VCS        `json:"vcs,omitempty"`
+		Variables []*appv1alpha1.Variable `json:"variables,omitempty"`
+	}{files, instance.Spec.VCS, instance.Spec.Variables})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
+// currentRunFingerprint returns the fingerprint a run started from the current spec would have
+func (r *WorkspaceHelper) currentRunFingerprint(instance *appv1alpha1.Workspace) (string, error) {
+	var files map[string]string
+	if instance.Spec.VCS == nil && generatesConfiguration(instance) {
+		var err error
+		files, err = r.getConfiguration(instance)
+		if err != nil {
+			return "", err
+		}
+	}
+	return runFingerprint(instance, files)
+}
+
+// recordRunFingerprint records the fingerprint of the current run. Runs whose fingerprint cannot
+// be computed are left without one, so they are never superseded.
+func (r *WorkspaceHelper) recordRunFingerprint(instance *appv1alpha1.Workspace) {
+	fingerprint, err := r.currentRunFingerprint(instance)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not compute run fingerprint", "RunID", instance.Status.RunID)
+	}
+	instance.Status.RunFingerprint = fingerprint
+}
+
+// specChangedDuringRun reports whether the fingerprint of the spec differs from the one the
+// current run was started with
+func specChangedDuringRun(instance *appv1alpha1.Workspace, fingerprint string) bool {
+	return instance.Status.RunFingerprint != "" && fingerprint != instance.Status.RunFingerprint
+}
+
+// supersedeRun cancels or discards the run in progress according to the run
+// supersede policy when its configuration, VCS repository or variables changed since it
+// was started. It returns true when the run was superseded and no longer holds up the
+// reconcile.
+func (r *WorkspaceHelper) supersedeRun(instance *appv1alpha1.Workspace) (bool, error) {
+	policy := instance.Spec.RunSupersedePolicy
+	if policy == "" || policy == appv1alpha1.RunSupersedePolicyWait || instance.Status.RunFingerprint == "" {
+		return false, nil
+	}
+	fingerprint, err := r.currentRunFingerprint(instance)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not compute run fingerprint", "RunID", instance.Status.RunID)
+		return false, err
+	}
+	if !specChangedDuringRun(instance, fingerprint) {
+		return false, nil
+	}
+
+	runID := instance.Status.RunID
+	comment := fmt.Sprintf("%s, superseded by generation %d of the spec", TerraformOperator, instance.Generation)
+	status, err := r.tfclient.SupersedeRun(runID, policy == appv1alpha1.RunSupersedePolicyCancel, comment)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not supersede run", "RunID", runID)
+		return false, err
+	}
+	if status == "" {
+		return false, nil
+	}
+
+	instance.Status.RunStatus = string(status)
+	instance.Status.ApprovalState = ""
+	setRunHistoryStatus(instance, runID, string(status), time.Now())
+	for i := range instance.Status.RunHistory {
+		if instance.Status.RunHistory[i].ID == runID {
+			instance.Status.RunHistory[i].Superseded = true
+		}
+	}
+	if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+		r.reqLogger.Error(err, "Failed to update Workspace status")
+		return false, err
+	}
+	r.recorder.Event(instance, corev1.EventTypeWarning, "WorkspaceEvent",
+		fmt.Sprintf("Run %s %s, superseded by a change to the spec", runID, status))
+	r.reqLogger.Info("Superseded run", "Organization", instance.Spec.Organization,
+		"RunID", runID, "RunStatus", status)
+	return true, nil
+}
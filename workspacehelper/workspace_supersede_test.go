@@ -0,0 +1,55 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSpecChangedDuringRun(t *testing.T) {
+	workspace := &v1alpha1.Workspace{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+	workspace.Spec.Variables = []*v1alpha1.Variable{{Key: "region", Value: "us-east-1"}}
+	files := map[string]string{"main.tf": "module \"operator\" {}"}
+	started, err := runFingerprint(workspace, files)
+	assert.NoError(t, err)
+	assert.False(t, specChangedDuringRun(workspace, started), "no run started yet")
+
+	workspace.Status.RunFingerprint = started
+	assert.False(t, specChangedDuringRun(workspace, started))
+
+	workspace.Spec.Variables[0].Value = "us-west-2"
+	current, err := runFingerprint(workspace, files)
+	assert.NoError(t, err)
+	assert.True(t, specChangedDuringRun(workspace, current))
+
+	workspace.Spec.Variables[0].Value = "us-east-1"
+	current, err = runFingerprint(workspace, map[string]string{"main.tf": "module \"other\" {}"})
+	assert.NoError(t, err)
+	assert.True(t, specChangedDuringRun(workspace, current), "configuration changed")
+}
+
+func TestTagsOnlyChangeDoesNotSupersedeRun(t *testing.T) {
+	workspace := &v1alpha1.Workspace{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+	workspace.Spec.VCS = &v1alpha1.VCS{RepoIdentifier: "org/repo", Branch: "main"}
+	started, err := runFingerprint(workspace, nil)
+	assert.NoError(t, err)
+	workspace.Status.RunFingerprint = started
+
+	workspace.Generation = 3
+	workspace.Spec.Tags = []string{"team-a"}
+	limit := int32(5)
+	workspace.Spec.RunHistoryLimit = &limit
+	current, err := runFingerprint(workspace, nil)
+	assert.NoError(t, err)
+	assert.False(t, specChangedDuringRun(workspace, current))
+
+	workspace.Spec.VCS.Branch = "release"
+	current, err = runFingerprint(workspace, nil)
+	assert.NoError(t, err)
+	assert.True(t, specChangedDuringRun(workspace, current), "VCS branch changed")
+}
@@ -0,0 +1,156 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Keys of the maintenance ConfigMap
+const (
+	maintenanceSuspendKey = "suspend"
+	maintenanceReasonKey  = "reason"
+)
+
+// Condition reasons of the Suspended condition
+const (
+	reasonSuspended              = "Suspended"
+	reasonMaintenanceMode        = "MaintenanceMode"
+	reasonNotSuspended           = "Active"
+	reasonMaintenanceCheckFailed = "MaintenanceCheckFailed"
+	reasonDeletionSuspended      = "DeletionSuspended"
+)
+
+// maintenance is the state of the operator-wide maintenance switch
+type maintenance struct {
+	enabled bool
+	reason  string
+}
+
+// NewMaintenanceReader returns a cache of the maintenance ConfigMap alone. The ConfigMap lives in the
+// operator namespace, which the cache of the manager does not cover when another namespace is watched.
+// The cache is synced before the controllers start.
+func NewMaintenanceReader(mgr manager.Manager, key types.NamespacedName) (client.Reader, error) {
+	maintenanceCluster, err := cluster.New(mgr.GetConfig(), func(options *cluster.Options) {
+		options.Scheme = mgr.GetScheme()
+		options.Namespace = key.Namespace
+		options.NewCache = cache.BuilderWithOptions(cache.Options{
+			SelectorsByObject: cache.SelectorsByObject{
+				&corev1.ConfigMap{}: {Field: fields.OneTermEqualSelector("metadata.name", key.Name)},
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := maintenanceCluster.GetCache().GetInformer(context.TODO(), &corev1.ConfigMap{}); err != nil {
+		return nil, err
+	}
+	if err := mgr.Add(maintenanceCluster); err != nil {
+		return nil, err
+	}
+	return maintenanceCluster.GetCache(), nil
+}
+
+// readMaintenance reads the maintenance ConfigMap, maintenance is off when no ConfigMap is configured or it does not exist
+func readMaintenance(reader client.Reader, key types.NamespacedName) (maintenance, error) {
+	if key.Name == "" {
+		return maintenance{}, nil
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := reader.Get(context.TODO(), key, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			return maintenance{}, nil
+		}
+		return maintenance{}, err
+	}
+	enabled, err := strconv.ParseBool(configMap.Data[maintenanceSuspendKey])
+	if err != nil && configMap.Data[maintenanceSuspendKey] != "" {
+		return maintenance{}, fmt.Errorf("invalid %s value in ConfigMap %s: %v", maintenanceSuspendKey, key, err)
+	}
+	return maintenance{enabled: enabled, reason: configMap.Data[maintenanceReasonKey]}, nil
+}
+
+// suspension returns the Suspended condition of a Workspace
+func suspension(instance *appv1alpha1.Workspace, mode maintenance) (bool, string, string) {
+	var reason, message string
+	switch {
+	case mode.enabled:
+		reason, message = reasonMaintenanceMode, "The operator is in maintenance mode"
+		if mode.reason != "" {
+			message = fmt.Sprintf("%s: %s", message, mode.reason)
+		}
+	case instance.Spec.Suspend:
+		reason, message = reasonSuspended, "Reconciling is suspended by spec.suspend"
+	default:
+		return false, reasonNotSuspended, ""
+	}
+	if instance.GetDeletionTimestamp() != nil && deletionPolicy(instance) != appv1alpha1.DeletionPolicyOrphan {
+		reason = reasonDeletionSuspended
+		message = fmt.Sprintf("%s. Deleting the workspace with deletionPolicy %s waits until reconciling is resumed",
+			message, deletionPolicy(instance))
+	}
+	return true, reason, message
+}
+
+// reconcileSuspension sets the Suspended condition and reports whether the Workspace is suspended
+func (r *WorkspaceHelper) reconcileSuspension(instance *appv1alpha1.Workspace) (bool, error) {
+	mode, err := readMaintenance(r.options.MaintenanceReader, r.options.MaintenanceConfigMap)
+	if err != nil {
+		setCondition(instance, appv1alpha1.ConditionSuspended, metav1.ConditionUnknown, reasonMaintenanceCheckFailed, err.Error())
+		return false, err
+	}
+	suspended, reason, message := suspension(instance, mode)
+	status := metav1.ConditionFalse
+	if suspended {
+		status = metav1.ConditionTrue
+	}
+	// Tell once that the deletion waits, not on every retry
+	previous := meta.FindStatusCondition(instance.Status.Conditions, appv1alpha1.ConditionSuspended)
+	if reason == reasonDeletionSuspended && (previous == nil || previous.Reason != reasonDeletionSuspended) {
+		r.recorder.Event(instance, corev1.EventTypeWarning, "WorkspaceEvent", message)
+	}
+	setCondition(instance, appv1alpha1.ConditionSuspended, status, reason, message)
+	return suspended, nil
+}
+
+// refreshSuspended only refreshes the status of the current run and its outputs,
+// without changing the Terraform Cloud workspace or starting, approving or deleting anything.
+func (r *WorkspaceHelper) refreshSuspended(instance *appv1alpha1.Workspace) (reconcile.Result, error) {
+	if instance.GetDeletionTimestamp() != nil {
+		if deletionPolicy(instance) == appv1alpha1.DeletionPolicyOrphan {
+			// Orphaning the workspace changes nothing in Terraform Cloud
+			return reconcile.Result{}, r.finalizeAndRemoveFinalizer(instance)
+		}
+		// The Suspended condition tells the deletion waits, retry with the backoff of the controller
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	if isPending(instance.Status.RunStatus) {
+		if err := r.refreshRun(instance); err != nil {
+			return reconcile.Result{}, phaseFailed(instance, reasonRunStatusFailed, err)
+		}
+	} else if instance.Status.RunID != "" {
+		if err := r.processFinishedRun(instance); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	setRunCondition(instance)
+	return reconcile.Result{RequeueAfter: requeueInterval}, nil
+}
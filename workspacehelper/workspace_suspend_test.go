@@ -0,0 +1,100 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// configMapReader serves a single ConfigMap
+type configMapReader struct {
+	configMap *corev1.ConfigMap
+}
+
+func (c configMapReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if c.configMap == nil || c.configMap.Name != key.Name || c.configMap.Namespace != key.Namespace {
+		return errors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, key.Name)
+	}
+	c.configMap.DeepCopyInto(obj.(*corev1.ConfigMap))
+	return nil
+}
+
+func (c configMapReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return nil
+}
+
+func TestReadMaintenance(t *testing.T) {
+	key := types.NamespacedName{Namespace: "terraform-k8s", Name: "maintenance"}
+	configMap := &corev1.ConfigMap{}
+	configMap.Namespace = key.Namespace
+	configMap.Name = key.Name
+
+	mode, err := readMaintenance(configMapReader{}, types.NamespacedName{})
+	assert.NoError(t, err)
+	assert.False(t, mode.enabled, "no maintenance ConfigMap configured")
+
+	mode, err = readMaintenance(configMapReader{}, key)
+	assert.NoError(t, err)
+	assert.False(t, mode.enabled, "missing maintenance ConfigMap")
+
+	configMap.Data = map[string]string{"suspend": "true", "reason": "incident 42"}
+	mode, err = readMaintenance(configMapReader{configMap}, key)
+	assert.NoError(t, err)
+	assert.Equal(t, maintenance{enabled: true, reason: "incident 42"}, mode)
+
+	configMap.Data = map[string]string{"suspend": "false"}
+	mode, err = readMaintenance(configMapReader{configMap}, key)
+	assert.NoError(t, err)
+	assert.False(t, mode.enabled)
+
+	configMap.Data = map[string]string{"suspend": "maybe"}
+	_, err = readMaintenance(configMapReader{configMap}, key)
+	assert.Error(t, err)
+}
+
+func TestSuspension(t *testing.T) {
+	workspace := &v1alpha1.Workspace{}
+	suspended, reason, _ := suspension(workspace, maintenance{})
+	assert.False(t, suspended)
+	assert.Equal(t, reasonNotSuspended, reason)
+
+	workspace.Spec.Suspend = true
+	suspended, reason, _ = suspension(workspace, maintenance{})
+	assert.True(t, suspended)
+	assert.Equal(t, reasonSuspended, reason)
+
+	suspended, reason, message := suspension(workspace, maintenance{enabled: true, reason: "incident 42"})
+	assert.True(t, suspended)
+	assert.Equal(t, reasonMaintenanceMode, reason)
+	assert.Equal(t, "The operator is in maintenance mode: incident 42", message)
+}
+
+func TestSuspendedDeletion(t *testing.T) {
+	now := metav1.Now()
+	workspace := &v1alpha1.Workspace{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now}}
+	workspace.Spec.Suspend = true
+	suspended, reason, message := suspension(workspace, maintenance{})
+	assert.True(t, suspended)
+	assert.Equal(t, reasonDeletionSuspended, reason)
+	assert.Equal(t, "Reconciling is suspended by spec.suspend. Deleting the workspace with deletionPolicy Destroy "+
+		"waits until reconciling is resumed", message)
+
+	workspace.Spec.DeletionPolicy = v1alpha1.DeletionPolicyOrphan
+	_, reason, _ = suspension(workspace, maintenance{})
+	assert.Equal(t, reasonSuspended, reason, "orphaning does not wait")
+
+	workspace.Spec.Suspend = false
+	suspended, _, _ = suspension(workspace, maintenance{})
+	assert.False(t, suspended)
+}
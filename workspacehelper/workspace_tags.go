@@ -0,0 +1,130 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// invalidTagCharacters matches what Terraform Cloud does not accept in tag names
+var invalidTagCharacters = regexp.MustCompile(`[^a-z0-9:_-]+`)
+
+// normalizeTag lowercases a tag and replaces the characters Terraform Cloud does not accept
+func normalizeTag(tag string) string {
+	return strings.Trim(invalidTagCharacters.ReplaceAllString(strings.ToLower(tag), "-"), "-")
+}
+
+// desiredTags returns the sorted tags of the spec and of the labels matching the tag label prefix
+func desiredTags(instance *appv1alpha1.Workspace) []string {
+	tags := map[string]bool{}
+	for _, tag := range instance.Spec.Tags {
+		if tag = normalizeTag(tag); tag != "" {
+			tags[tag] = true
+		}
+	}
+	if prefix := instance.Spec.TagLabelPrefix; prefix != "" {
+		for key, value := range instance.GetLabels() {
+			if !strings.HasPrefix(key, prefix) || key == prefix {
+				continue
+			}
+			tag := strings.TrimPrefix(key, prefix)
+			if value != "" && value != "true" {
+				tag = fmt.Sprintf("%s:%s", tag, value)
+			}
+			if tag = normalizeTag(tag); tag != "" {
+				tags[tag] = true
+			}
+		}
+	}
+
+	desired := []string{}
+	for tag := range tags {
+		desired = append(desired, tag)
+	}
+	sort.Strings(desired)
+	return desired
+}
+
+// tagChanges returns the tags to add to and remove from the workspace. Tags the operator added
+// before and that are no longer desired are removed, and with the Exclusive policy any other tag too.
+func tagChanges(desired, current, managed []string, exclusive bool) ([]string, []string) {
+	isDesired := map[string]bool{}
+	for _, tag := range desired {
+		isDesired[tag] = true
+	}
+	isCurrent := map[string]bool{}
+	for _, tag := range current {
+		isCurrent[tag] = true
+	}
+
+	add := []string{}
+	for _, tag := range desired {
+		if !isCurrent[tag] {
+			add = append(add, tag)
+		}
+	}
+	remove := []string{}
+	for _, tag := range current {
+		if !isDesired[tag] && (exclusive || contains(managed, tag)) {
+			remove = append(remove, tag)
+		}
+	}
+	return add, remove
+}
+
+// managedTags returns the desired tags the operator added, now or before, leaving out the tags
+// the workspace already had so they are kept once they are no longer desired
+func managedTags(desired, managed, add []string) []string {
+	tags := []string{}
+	for _, tag := range desired {
+		if contains(add, tag) || contains(managed, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
+
+// reconcileTags adds the tags of the spec and labels to the workspace and removes stale ones
+func (r *WorkspaceHelper) reconcileTags(instance *appv1alpha1.Workspace) error {
+	desired := desiredTags(instance)
+	if len(desired) == 0 && len(instance.Status.Tags) == 0 && instance.Spec.TagPolicy != appv1alpha1.TagPolicyExclusive {
+		return nil
+	}
+
+	current, err := r.tfclient.GetWorkspaceTags(instance.Status.WorkspaceID)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not get workspace tags", "WorkspaceID", instance.Status.WorkspaceID)
+		return err
+	}
+	add, remove := tagChanges(desired, current, instance.Status.Tags, instance.Spec.TagPolicy == appv1alpha1.TagPolicyExclusive)
+	if err := r.tfclient.UpdateWorkspaceTags(instance.Status.WorkspaceID, add, remove); err != nil {
+		r.reqLogger.Error(err, "Could not update workspace tags", "WorkspaceID", instance.Status.WorkspaceID)
+		return err
+	}
+	if len(add) > 0 || len(remove) > 0 {
+		r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent",
+			fmt.Sprintf("Updated tags of workspace %s, added %v and removed %v", instance.Status.WorkspaceID, add, remove))
+	}
+
+	managed := managedTags(desired, instance.Status.Tags, add)
+	if !reflect.DeepEqual(instance.Status.Tags, managed) {
+		instance.Status.Tags = managed
+		if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+			r.reqLogger.Error(err, "Failed to update Workspace tags")
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	assert.Equal(t, "team:payments", normalizeTag("Team:Payments"))
+	assert.Equal(t, "app-kubernetes-io-name:web", normalizeTag("app.kubernetes.io/name:web"))
+	assert.Equal(t, "", normalizeTag("../"))
+}
+
+func TestDesiredTags(t *testing.T) {
+	workspace := &v1alpha1.Workspace{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+		"tags.app.terraform.io/team":    "payments",
+		"tags.app.terraform.io/pci":     "true",
+		"tags.app.terraform.io/":        "ignored",
+		"app.kubernetes.io/name":        "web",
+		"tags.app.terraform.io/Tier.V2": "",
+	}}}
+	workspace.Spec.Tags = []string{"prod", "Prod", "k8s"}
+	assert.Equal(t, []string{"k8s", "prod"}, desiredTags(workspace), "labels are only mapped with a prefix")
+
+	workspace.Spec.TagLabelPrefix = "tags.app.terraform.io/"
+	assert.Equal(t, []string{"k8s", "pci", "prod", "team:payments", "tier-v2"}, desiredTags(workspace))
+}
+
+func TestTagChanges(t *testing.T) {
+	desired := []string{"prod", "team:payments"}
+	current := []string{"prod", "team:billing", "manual"}
+	managed := []string{"prod", "team:billing"}
+
+	add, remove := tagChanges(desired, current, managed, false)
+	assert.Equal(t, []string{"team:payments"}, add)
+	assert.Equal(t, []string{"team:billing"}, remove, "tags added by hand are kept")
+
+	add, remove = tagChanges(desired, current, managed, true)
+	assert.Equal(t, []string{"team:payments"}, add)
+	assert.Equal(t, []string{"team:billing", "manual"}, remove)
+
+	add, remove = tagChanges(desired, desired, desired, true)
+	assert.Empty(t, add)
+	assert.Empty(t, remove)
+}
+
+func TestManagedTags(t *testing.T) {
+	desired := []string{"manual", "prod", "team:payments"}
+	assert.Equal(t, []string{"prod", "team:payments"}, managedTags(desired, []string{"prod", "team:billing"}, []string{"team:payments"}),
+		"tags the workspace already had are not managed")
+	assert.Nil(t, managedTags(desired, nil, nil))
+	assert.Nil(t, managedTags(nil, []string{"prod"}, nil))
+}
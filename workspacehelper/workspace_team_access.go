@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// reconcileTeamAccess grants the teams of the spec access to the workspace and revokes the
+// access the operator granted before to teams that are no longer in the spec. Access granted
+// outside of the operator is updated to the spec but never revoked.
+func (r *WorkspaceHelper) reconcileTeamAccess(instance *appv1alpha1.Workspace) error {
+	if len(instance.Spec.TeamAccess) == 0 && len(instance.Status.TeamAccess) == 0 {
+		return nil
+	}
+	if err := validateTeamAccess(instance.Spec.TeamAccess); err != nil {
+		return err
+	}
+
+	workspaceID := instance.Status.WorkspaceID
+	current, err := r.tfclient.GetTeamAccess(workspaceID)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not get team access", "WorkspaceID", workspaceID)
+		return err
+	}
+	var teamIDs map[string]string
+	if len(instance.Spec.TeamAccess) > 0 {
+		if teamIDs, err = r.tfclient.GetTeamIDs(instance.Spec.Organization); err != nil {
+			r.reqLogger.Error(err, "Could not list teams", "Organization", instance.Spec.Organization)
+			return err
+		}
+	}
+
+	statuses := []appv1alpha1.TeamAccessStatus{}
+	desired := map[string]bool{}
+	for _, spec := range instance.Spec.TeamAccess {
+		teamID, err := resolveTeamID(spec.Team, teamIDs)
+		if err != nil {
+			return err
+		}
+		desired[teamID] = true
+
+		access := current[teamID]
+		granted := access == nil || grantedTeamAccess(instance.Status.TeamAccess, access.ID)
+		if access == nil {
+			if access, err = r.tfclient.AddTeamAccess(workspaceID, teamID, spec); err != nil {
+				r.reqLogger.Error(err, "Could not add team access", "WorkspaceID", workspaceID, "Team", spec.Team)
+				return err
+			}
+			r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent",
+				fmt.Sprintf("Granted team %s %s access", spec.Team, spec.Access))
+		} else if !teamAccessMatches(spec, access) {
+			if access, err = r.tfclient.UpdateTeamAccess(access.ID, spec); err != nil {
+				r.reqLogger.Error(err, "Could not update team access", "WorkspaceID", workspaceID, "Team", spec.Team)
+				return err
+			}
+			r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent",
+				fmt.Sprintf("Changed access of team %s to %s", spec.Team, spec.Access))
+		}
+		if !granted {
+			continue
+		}
+		statuses = append(statuses, appv1alpha1.TeamAccessStatus{
+			Team:   spec.Team,
+			TeamID: teamID,
+			ID:     access.ID,
+			Access: spec.Access,
+		})
+	}
+
+	for _, previous := range instance.Status.TeamAccess {
+		access := current[previous.TeamID]
+		if desired[previous.TeamID] || access == nil {
+			continue
+		}
+		if err := r.tfclient.RemoveTeamAccess(access.ID); err != nil {
+			r.reqLogger.Error(err, "Could not remove team access", "WorkspaceID", workspaceID, "Team", previous.Team)
+			return err
+		}
+		r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent",
+			fmt.Sprintf("Revoked access of team %s", previous.Team))
+	}
+
+	if len(statuses) == 0 {
+		statuses = nil
+	}
+	if !reflect.DeepEqual(instance.Status.TeamAccess, statuses) {
+		instance.Status.TeamAccess = statuses
+		if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+			r.reqLogger.Error(err, "Failed to update team access status")
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package workspacehelper
+
+import (
+	"context"
+	"fmt"
+
+	appv1alpha1 "github.com/hashicorp/terraform-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// updateVCSRepo keeps the VCS connection of the workspace in line with the spec, connecting it
+// to the repository of the spec or disconnecting it when the Workspace switched to a module.
+// It reports whether the configuration of the workspace changed and needs a new run.
+func (r *WorkspaceHelper) updateVCSRepo(instance *appv1alpha1.Workspace) (bool, error) {
+	if instance.Spec.VCS == nil && !generatesConfiguration(instance) {
+		return false, nil
+	}
+	ws, err := r.tfclient.Client.Workspaces.ReadByID(context.TODO(), instance.Status.WorkspaceID)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not read workspace", "WorkspaceID", instance.Status.WorkspaceID)
+		return false, err
+	}
+
+	var msg string
+	if instance.Spec.VCS == nil {
+		if ws.VCSRepo == nil {
+			return false, nil
+		}
+		if _, err := r.tfclient.Client.Workspaces.RemoveVCSConnectionByID(context.TODO(), ws.ID); err != nil {
+			r.reqLogger.Error(err, "Could not remove VCS connection", "WorkspaceID", ws.ID)
+			return false, err
+		}
+		msg = fmt.Sprintf("Disconnected workspace %s from repository %s to run the module", ws.ID, ws.VCSRepo.Identifier)
+	} else {
+		updated, err := r.tfclient.UpdateVCSRepo(instance.Spec.VCS, ws)
+		if err != nil {
+			r.reqLogger.Error(err, "Could not update VCS connection", "WorkspaceID", ws.ID)
+			return false, err
+		} else if !updated {
+			return false, nil
+		}
+		msg = fmt.Sprintf("Connected workspace %s to repository %s", ws.ID, instance.Spec.VCS.RepoIdentifier)
+		if instance.Spec.VCS.Branch != "" {
+			msg = fmt.Sprintf("%s on branch %s", msg, instance.Spec.VCS.Branch)
+		}
+	}
+
+	// A configuration version uploaded for the previous mode must not be used for the next run,
+	// and the run waits until the new repository is ingressed
+	instance.Status.ConfigVersionID = ""
+	instance.Status.VCSRunPending = instance.Spec.VCS != nil
+	if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+		r.reqLogger.Error(err, "Failed to update Workspace status")
+		return false, err
+	}
+	r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent", msg)
+	r.reqLogger.Info("Updated VCS connection", "Organization", instance.Spec.Organization, "WorkspaceID", ws.ID)
+	return true, nil
+}
+
+// updateTagsRegex sets the Git tags that trigger runs of a VCS workspace. Like trigger prefixes,
+// the tags regex is only managed while it is set in the spec.
+func (r *WorkspaceHelper) updateTagsRegex(instance *appv1alpha1.Workspace) error {
+	vcs := instance.Spec.VCS
+	if vcs == nil || vcs.TagsRegex == "" {
+		return nil
+	}
+	workspaceID := instance.Status.WorkspaceID
+	current, err := r.tfclient.GetTagsRegex(workspaceID)
+	if err != nil {
+		r.reqLogger.Error(err, "Could not read tags regex", "WorkspaceID", workspaceID)
+		return err
+	}
+	if current == vcs.TagsRegex {
+		return nil
+	}
+	if err := r.tfclient.UpdateTagsRegex(workspaceID, vcs); err != nil {
+		r.reqLogger.Error(err, "Could not update tags regex", "WorkspaceID", workspaceID)
+		return err
+	}
+	r.recorder.Event(instance, corev1.EventTypeNormal, "WorkspaceEvent",
+		fmt.Sprintf("Runs of workspace %s are triggered by Git tags matching %s", workspaceID, vcs.TagsRegex))
+	return nil
+}
+
+// deleteTerraformConfig removes the module configuration of a Workspace that switched to VCS,
+// so switching back to the module uploads it again
+func (r *WorkspaceHelper) deleteTerraformConfig(instance *appv1alpha1.Workspace) error {
+	found := &corev1.ConfigMap{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, found)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(found, instance) {
+		return nil
+	}
+	r.reqLogger.Info("Deleting Terraform ConfigMap of the module", "Namespace", instance.Namespace, "Name", instance.Name)
+	if err := r.client.Delete(context.TODO(), found); err != nil && !k8serrors.IsNotFound(err) {
+		r.reqLogger.Error(err, "Failed to delete Terraform ConfigMap")
+		return err
+	}
+	return nil
+}